@@ -0,0 +1,76 @@
+package vue
+
+// asyncContext is the context of methods called asynchronously on goroutines by Go.
+// Changes are posted to the update loop, fields are read by waiting on the update loop.
+// Data is returned as is, to be accessed by Update with the context of the update loop.
+type asyncContext struct {
+	vm *ViewModel
+}
+
+// Data returns the data for the component.
+// Data is required to be accessed by Update.
+func (vctx asyncContext) Data() interface{} {
+	return vctx.vm.data.Interface()
+}
+
+// Props returns the props of the subcomponent once read by the update loop.
+// Typed props are required to be accessed by Update.
+func (vctx asyncContext) Props() interface{} {
+	props := make(chan interface{}, 1)
+	updates.post(func() {
+		props <- vctx.vm.Props()
+	})
+	return <-props
+}
+
+// Get returns the data field value once read by the update loop.
+func (vctx asyncContext) Get(field string) interface{} {
+	value := make(chan interface{}, 1)
+	updates.post(func() {
+		value <- vctx.vm.Get(field)
+	})
+	return <-value
+}
+
+// Set assigns the data field to the given value by the update loop.
+func (vctx asyncContext) Set(field string, value interface{}) {
+	vctx.vm.Update(func(vctx Context) {
+		vctx.Set(field, value)
+	})
+}
+
+// Go asynchronously calls the given method with optional arguments on a goroutine.
+func (vctx asyncContext) Go(method string, args ...interface{}) {
+	vctx.vm.Go(method, args...)
+}
+
+// Emit dispatches the given event with optional arguments by the update loop.
+func (vctx asyncContext) Emit(event string, args ...interface{}) {
+	vctx.vm.Update(func(vctx Context) {
+		vctx.Emit(event, args...)
+	})
+}
+
+// NextTick calls the function after the next update is rendered into the document.
+func (vctx asyncContext) NextTick(function func()) {
+	vctx.vm.NextTick(function)
+}
+
+// Update calls the function with the context of the update loop, then renders the component.
+// The context of the function accesses the component synchronously.
+func (vctx asyncContext) Update(function func(Context)) {
+	vctx.vm.Update(function)
+}
+
+// Watch watches the field path by the update loop.
+// The returned function unwatches the field path by the update loop.
+func (vctx asyncContext) Watch(field string, function interface{}, options ...WatchOption) func() {
+	unwatch := make(chan func(), 1)
+	updates.post(func() {
+		unwatch <- vctx.vm.Watch(field, function, options...)
+	})
+	stop := <-unwatch
+	return func() {
+		updates.post(stop)
+	}
+}
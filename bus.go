@@ -1,9 +1,5 @@
 package vue
 
-import (
-	"reflect"
-)
-
 // bus contains subscriptions of events to methods.
 type bus struct {
 	parent *bus
@@ -13,7 +9,7 @@ type bus struct {
 
 // caller calls a method with optional arguments.
 type caller interface {
-	call(method string, args []reflect.Value)
+	call(method string, args []interface{})
 }
 
 // newBus creates a new event bus.
@@ -36,18 +32,13 @@ func (bus *bus) pub(event, method string, args []interface{}) {
 		return
 	}
 
-	values := make([]reflect.Value, 0, len(args))
-	for _, arg := range args {
-		values = append(values, reflect.ValueOf(arg))
-	}
-
 	if method != "" {
-		bus.caller.call(method, values)
+		bus.caller.call(method, args)
 		return
 	}
 
 	for method := range methods {
-		bus.caller.call(method, values)
+		bus.caller.call(method, args)
 	}
 }
 
@@ -0,0 +1,272 @@
+package vue
+
+import (
+	"fmt"
+	"github.com/cbroglie/mustache"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"sort"
+	"strings"
+)
+
+// tmplNode is a node of the compiled template.
+// Templates are compiled once per component, then executed on every render.
+type tmplNode struct {
+	typ      html.NodeType
+	data     string
+	atom     atom.Atom
+	attrs    []html.Attribute
+	dirs     []*directive
+	text     *mustache.Template
+	names    []string
+	chain    []condition
+	children []*tmplNode
+	slots    map[string]*slotContent
+	hasSub   bool
+}
+
+// directive is a compiled vue attribute with the parsed expression of its value.
+// For example: v-on:click.enter="Add" -> {v-on, click.enter, Add}
+type directive struct {
+	typ, part string
+	key, val  string
+	expr      expr
+	loop      loop
+}
+
+// condition is an element following a vue if element, e.g. v-else-if or v-else.
+// The expression of v-else is nil.
+type condition struct {
+	node *tmplNode
+	expr expr
+}
+
+// compile compiles the template of the component.
+func (comp *Comp) compile() (*tmplNode, error) {
+	node, err := parseNode(comp.tmpl)
+	if err != nil {
+		return nil, err
+	}
+	return comp.compileNode(node)
+}
+
+// compileNode recursively compiles the html node.
+// Conditional elements are chained to the preceding vue if element, other nodes than elements and text are dropped.
+// The children of subcomponent elements are compiled into slot contents.
+func (comp *Comp) compileNode(node *html.Node) (*tmplNode, error) {
+	tmpl := &tmplNode{typ: node.Type, data: node.Data, atom: node.DataAtom}
+	_, isSub := comp.subs[node.Data]
+	switch node.Type {
+	case html.TextNode:
+		if strings.Contains(node.Data, "{{") {
+			var err error
+			if tmpl.text, err = mustache.ParseString(node.Data); err != nil {
+				return nil, comp.newError(err, nodeLocation(node.Parent), "{{ }}")
+			}
+			tmpl.names = tagNames(tmpl.text.Tags())
+		}
+		return tmpl, nil
+	case html.ElementNode:
+		// Slot elements are executed by the parent, like subcomponents.
+		tmpl.hasSub = isSub || node.Data == "slot"
+		for _, attr := range node.Attr {
+			if !strings.HasPrefix(attr.Key, v) {
+				tmpl.attrs = append(tmpl.attrs, attr)
+				continue
+			}
+			dir, err := comp.compileAttr(attr)
+			if err != nil {
+				return nil, comp.newError(err, nodeLocation(node), dir.String())
+			}
+			tmpl.dirs = append(tmpl.dirs, dir)
+		}
+		orderDirs(tmpl.dirs)
+		if dir := tmpl.findDir(vSlot); dir != nil && !isSub && node.Data != "template" {
+			err := fmt.Errorf("v-slot is not on a subcomponent nor template element")
+			return nil, comp.newError(err, nodeLocation(node), dir.String())
+		}
+	}
+
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != html.ElementNode && child.Type != html.TextNode {
+			continue
+		}
+		childTmpl, err := comp.compileNode(child)
+		if err != nil {
+			return nil, err
+		}
+		if dir := childTmpl.condition(); dir != nil {
+			if !tmpl.chainCondition(condition{node: childTmpl, expr: dir.expr}) {
+				err := fmt.Errorf("%s without a preceding v-if element", dir.typ)
+				return nil, comp.newError(err, nodeLocation(child), dir.String())
+			}
+			continue
+		}
+		if dir := childTmpl.findDir(vSlot); dir != nil && !isSub {
+			err := fmt.Errorf("v-slot template is not a child of a subcomponent element")
+			return nil, comp.newError(err, nodeLocation(child), dir.String())
+		}
+		tmpl.children = append(tmpl.children, childTmpl)
+	}
+	for _, child := range tmpl.children {
+		tmpl.hasSub = tmpl.hasSub || child.hasSub
+	}
+	if isSub {
+		if err := comp.compileSlots(tmpl, node); err != nil {
+			return nil, err
+		}
+	}
+	return tmpl, nil
+}
+
+// tagNames returns the top level names of the mustache tags, e.g. the fields read by the text.
+// For example: {{ User.Name }} -> User
+func tagNames(tags []mustache.Tag) []string {
+	var names []string
+	for _, tag := range tags {
+		name := strings.Split(tag.Name(), ".")[0]
+		if name != "" {
+			names = append(names, name)
+		}
+		switch tag.Type() {
+		case mustache.Section, mustache.InvertedSection:
+			names = append(names, tagNames(tag.Tags())...)
+		}
+	}
+	return names
+}
+
+// compileAttr compiles the vue attribute.
+// The directive is returned with errors to describe the attribute.
+func (comp *Comp) compileAttr(attr html.Attribute) (*directive, error) {
+	typ := attrDirective(attr.Key)
+	part := strings.TrimPrefix(attr.Key[len(typ):], ":")
+	dir := &directive{typ: typ, part: part, key: attr.Key, val: attr.Val}
+
+	var err error
+	switch typ {
+	case vBind, vHtml, vIf, vElseIf, vShow:
+		dir.expr, err = comp.expr(attr.Val)
+	case vElse:
+	case vSlot:
+		if attr.Val != "" {
+			if dir.expr, err = comp.expr(attr.Val); err == nil {
+				if _, ok := dir.expr.(*identExpr); !ok {
+					err = fmt.Errorf("v-slot value is not a variable name: %s", attr.Val)
+				}
+			}
+		}
+	case vFor:
+		if dir.loop, err = parseLoop(attr.Val); err == nil {
+			dir.expr, err = comp.expr(dir.loop.src)
+		}
+	case vModel:
+		if dir.expr, err = comp.expr(attr.Val); err == nil && !isPath(dir.expr) {
+			err = fmt.Errorf("v-model value is not a field path: %s", attr.Val)
+		}
+	case vOn:
+		if dir.expr, err = comp.expr(attr.Val); err == nil {
+			if _, _, ok := handlerMethod(dir.expr); !ok {
+				err = fmt.Errorf("v-on value is not a method call: %s", attr.Val)
+			}
+		}
+	default:
+		err = fmt.Errorf("unknown vue attribute: %v", typ)
+	}
+	return dir, err
+}
+
+// newError creates an error of the component at compilation.
+func (comp *Comp) newError(err error, location, directive string) *Error {
+	return &Error{Component: comp.name, Location: location, Directive: directive, Err: err}
+}
+
+// String returns the attribute of the directive, e.g. for errors.
+// For example: v-if="Done"
+func (dir *directive) String() string {
+	return fmt.Sprintf("%s=%q", dir.key, dir.val)
+}
+
+// findDir finds the directive of the type of the element.
+// Returns nil if the element has no such directive.
+func (tmpl *tmplNode) findDir(typ string) *directive {
+	for _, dir := range tmpl.dirs {
+		if dir.typ == typ {
+			return dir
+		}
+	}
+	return nil
+}
+
+// condition removes the v-else-if or v-else directive from the element.
+// Returns nil if the element is not conditional.
+func (tmpl *tmplNode) condition() *directive {
+	for i, dir := range tmpl.dirs {
+		if dir.typ == vElseIf || dir.typ == vElse {
+			tmpl.dirs = append(tmpl.dirs[:i], tmpl.dirs[i+1:]...)
+			return dir
+		}
+	}
+	return nil
+}
+
+// chainCondition chains the condition to the last vue if element of the children.
+// Whitespace between the elements is dropped.
+// Returns false if the last element is not a vue if element.
+func (tmpl *tmplNode) chainCondition(cond condition) bool {
+	i := len(tmpl.children) - 1
+	for i >= 0 && tmpl.children[i].isSpace() {
+		i--
+	}
+	if i < 0 || !tmpl.children[i].isOpenIf() {
+		return false
+	}
+	prev := tmpl.children[i]
+	prev.chain = append(prev.chain, cond)
+	prev.hasSub = prev.hasSub || cond.node.hasSub
+	tmpl.children = tmpl.children[:i+1]
+	return true
+}
+
+// isSpace returns true if the node is whitespace text.
+func (tmpl *tmplNode) isSpace() bool {
+	return tmpl.typ == html.TextNode && strings.TrimSpace(tmpl.data) == ""
+}
+
+// isOpenIf returns true if the node is a vue if element whose chain does not end with v-else.
+func (tmpl *tmplNode) isOpenIf() bool {
+	if n := len(tmpl.chain); n > 0 && tmpl.chain[n-1].expr == nil {
+		return false
+	}
+	for _, dir := range tmpl.dirs {
+		if dir.typ == vIf {
+			return true
+		}
+	}
+	return false
+}
+
+// firstElement finds the first child element of the compiled template.
+// Returns false if a child element is not found.
+func (tmpl *tmplNode) firstElement() (*tmplNode, bool) {
+	if tmpl == nil {
+		return nil, false
+	}
+	for _, child := range tmpl.children {
+		if child.typ == html.ElementNode {
+			return child, true
+		}
+	}
+	return nil, false
+}
+
+// orderDirs orders the directives which orders the template execution.
+func orderDirs(dirs []*directive) {
+	order := make(map[string]int, len(attrOrder))
+	for i, directive := range attrOrder {
+		order[directive] = i
+	}
+	sort.SliceStable(dirs, func(i, j int) bool {
+		return order[dirs[i].typ] < order[dirs[j].typ]
+	})
+}
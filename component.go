@@ -8,49 +8,92 @@ import (
 
 // Comp is a vue component.
 type Comp struct {
-	el       string
-	tmpl     string
-	data     interface{}
-	methods  map[string]reflect.Value
-	computed map[string]reflect.Value
-	watchers map[string]reflect.Value
-	props    map[string]struct{}
-	subs     map[string]*Comp
-	isSub    bool
+	name      string
+	el        string
+	tmpl      string
+	root      *tmplNode
+	data      interface{}
+	methods   map[string]reflect.Value
+	computed  map[string]reflect.Value
+	setters   map[string]reflect.Value
+	watchers  []*watcher
+	props     map[string]*prop
+	propAttrs map[string]string
+	propsType reflect.Type
+	emits     map[string]struct{}
+	hooks     map[hook][]func(Context)
+	subs      map[string]*Comp
+	exprs     map[string]expr
+	isSub     bool
+
+	errorHandler  func(Context, *Error)
+	errorCaptured []func(Context, *Error) bool
+	err           error
 }
 
 // Component creates a new component from the given options.
 func Component(options ...Option) *Comp {
 	methods := make(map[string]reflect.Value, 0)
 	computed := make(map[string]reflect.Value, 0)
-	watches := make(map[string]reflect.Value, 0)
-	props := make(map[string]struct{}, 0)
+	setters := make(map[string]reflect.Value, 0)
+	props := make(map[string]*prop, 0)
+	propAttrs := make(map[string]string, 0)
+	emits := make(map[string]struct{}, 0)
+	hooks := make(map[hook][]func(Context), 0)
 	subs := make(map[string]*Comp, 0)
+	exprs := make(map[string]expr, 0)
 
 	comp := &Comp{
-		data:     struct{}{},
-		methods:  methods,
-		computed: computed,
-		watchers: watches,
-		props:    props,
-		subs:     subs,
+		data:      struct{}{},
+		methods:   methods,
+		computed:  computed,
+		setters:   setters,
+		props:     props,
+		propAttrs: propAttrs,
+		emits:     emits,
+		hooks:     hooks,
+		subs:      subs,
+		exprs:     exprs,
 	}
 	for _, option := range options {
 		option(comp)
 	}
+	if comp.name == "" {
+		comp.name = comp.el
+	}
+	// Errors of options are kept over errors of compilation.
+	root, err := comp.compile()
+	if comp.err == nil {
+		comp.err = err
+	}
+	comp.root = root
 	return comp
 }
 
 // newData creates new data from the function.
 // Without a function the data of the component is returned.
-func (comp *Comp) newData() reflect.Value {
+func (comp *Comp) newData() (reflect.Value, error) {
 	value := reflect.ValueOf(comp.data)
 	if value.Type().Kind() != reflect.Func {
-		return value
+		return value, nil
 	}
 	rets := value.Call(nil)
 	if n := len(rets); n != 1 {
-		must(fmt.Errorf("invalid return length: %d", n))
+		return reflect.ValueOf(struct{}{}), fmt.Errorf("invalid return length of data: %d", n)
+	}
+	return rets[0], nil
+}
+
+// expr returns the parsed expression of the source.
+// Expressions are parsed once and cached by source.
+func (comp *Comp) expr(src string) (expr, error) {
+	if expr, ok := comp.exprs[src]; ok {
+		return expr, nil
+	}
+	expr, err := parseExpr(src)
+	if err != nil {
+		return nil, err
 	}
-	return rets[0]
+	comp.exprs[src] = expr
+	return expr, nil
 }
@@ -0,0 +1,82 @@
+package vue
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// getComputed returns the cached value of the computed.
+// The computed is calculated again once invalidated, then marked as changed only if its value changed.
+func (vm *ViewModel) getComputed(computed string, function reflect.Value) interface{} {
+	if _, ok := vm.computeds[computed]; ok {
+		return vm.state[computed]
+	}
+	value := vm.compute(computed, function)
+	vm.mapField(computed, value)
+	return value
+}
+
+// compute calls the given function of the computed and returns the first element.
+// The fields read by the function are cached unless the function reads data untracked.
+// Errors of the function, e.g. panics, are handled and return nil.
+func (vm *ViewModel) compute(computed string, function reflect.Value) (value interface{}) {
+	defer vm.catch("computed " + computed)
+	tracking := vm.startTracking()
+	defer vm.stopTracking()
+	values := []reflect.Value{reflect.ValueOf(vm)}
+	rets := function.Call(values)
+	if !tracking.untracked {
+		vm.computeds[computed] = tracking
+	}
+	return rets[0].Interface()
+}
+
+// invalidate recursively invalidates the cached computed which read the field.
+// All computed are invalidated by an empty field, e.g. for data mutated without Set.
+func (vm *ViewModel) invalidate(field string) {
+	for computed, tracking := range vm.computeds {
+		if _, ok := tracking.fields[field]; ok || field == "" {
+			delete(vm.computeds, computed)
+			vm.invalidate(computed)
+		}
+	}
+}
+
+// shadowed returns true if the computed is shadowed by a data field or prop.
+func (vm *ViewModel) shadowed(computed string) bool {
+	if _, ok := vm.props[computed]; ok {
+		return true
+	}
+	return reflect.Indirect(vm.data).FieldByName(computed).IsValid()
+}
+
+// setter returns the setter of the writable computed.
+// Returns false if the field is not a computed with a valid setter.
+func (vm *ViewModel) setter(field string) (reflect.Value, bool) {
+	setter, ok := vm.comp.setters[field]
+	if _, isComputed := vm.comp.computed[field]; !ok || !isComputed || vm.shadowed(field) || checkSetter(setter) != nil {
+		return reflect.Value{}, false
+	}
+	return setter, true
+}
+
+// checkSetter returns an error if the setter is not a function of the context and the value.
+func checkSetter(setter reflect.Value) error {
+	if setter.Kind() != reflect.Func {
+		return fmt.Errorf("computed setter is not a function: %v", setter.Kind())
+	}
+	if setter.Type().NumIn() != 2 {
+		return fmt.Errorf("computed setter is not a function of the context and value: %s", setter.Type())
+	}
+	return nil
+}
+
+// setComputed calls the setter of the computed with the value converted into the parameter type.
+func (vm *ViewModel) setComputed(computed string, setter reflect.Value, value interface{}) {
+	values, err := convertArgs(setter.Type(), 1, []interface{}{value})
+	if err != nil {
+		vm.handleError(vm.newError(err, "", "Set "+computed))
+		return
+	}
+	setter.Call(append([]reflect.Value{reflect.ValueOf(vm)}, values...))
+}
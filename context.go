@@ -3,6 +3,7 @@ package vue
 import (
 	"fmt"
 	"reflect"
+	"strings"
 )
 
 // Context is received by functions to interact with the component.
@@ -12,69 +13,203 @@ type Context interface {
 	Set(field string, value interface{})
 	Go(method string, args ...interface{})
 	Emit(event string, args ...interface{})
+	NextTick(function func())
+	Update(function func(Context))
+	Watch(field string, function interface{}, options ...WatchOption) func()
+	Props() interface{}
 }
 
 // Data returns the data for the component.
 // Props and computed are excluded from data.
+// Data may be mutated without Set, which renders the whole component.
 func (vm *ViewModel) Data() interface{} {
+	vm.markData()
 	return vm.data.Interface()
 }
 
+// Props returns the props of the subcomponent.
+// Typed props of PropsOf are a pointer to the struct, otherwise a map of the props.
+// Props are passed by the parent and not to be mutated.
+func (vm *ViewModel) Props() interface{} {
+	for name := range vm.props {
+		vm.track(name)
+	}
+	if vm.typedProps.IsValid() {
+		return vm.typedProps.Interface()
+	}
+	props := make(map[string]interface{}, len(vm.props))
+	for name, value := range vm.props {
+		props[name] = value
+	}
+	return props
+}
+
 // Get returns the data field value.
 // Props and computed are included to get.
-// Computed may be calculated as needed.
+// Computed are cached until a field read by the computed changes.
+// Unknown fields are handled as errors and return nil.
 func (vm *ViewModel) Get(field string) interface{} {
+	vm.track(field)
+	if function, ok := vm.comp.computed[field]; ok && !vm.shadowed(field) {
+		return vm.getComputed(field, function)
+	}
 	if value, ok := vm.state[field]; ok {
 		return value
 	}
-
-	function, ok := vm.comp.computed[field]
-	if !ok {
-		must(fmt.Errorf("unknown data field: %s", field))
-	}
-	value := vm.compute(function)
-	vm.mapField(field, value)
-	return value
+	vm.handleError(vm.newError(fmt.Errorf("unknown data field: %s", field), "", "Get"))
+	return nil
 }
 
 // Set assigns the data field to the given value.
-// Props and computed are excluded to set.
+// The field may be a path of nested fields, e.g. User.Name.
+// Props are excluded to set, computed are set by their setter.
+// Unknown fields and values of other types are handled as errors.
 func (vm *ViewModel) Set(field string, value interface{}) {
-	data := reflect.Indirect(vm.data)
-	oldVal := reflect.Indirect(data.FieldByName(field))
+	if setter, ok := vm.setter(field); ok {
+		vm.setComputed(field, setter, value)
+		return
+	}
+
+	oldVal, err := vm.field(field)
+	if err != nil {
+		vm.handleError(vm.newError(err, "", "Set"))
+		return
+	}
 	newVal := reflect.Indirect(reflect.ValueOf(value))
+	if !newVal.IsValid() || !newVal.Type().AssignableTo(oldVal.Type()) {
+		err := fmt.Errorf("cannot set data field %s of type %s to %T", field, oldVal.Type(), value)
+		vm.handleError(vm.newError(err, "", "Set"))
+		return
+	}
 
 	oldVal.Set(newVal)
-	vm.mapField(field, value)
+	name := strings.Split(field, ".")[0]
+	vm.markField(name)
+	vm.mapField(name, reflect.Indirect(vm.data).FieldByName(name).Interface())
 }
 
-// Go asynchronously calls the given method with optional arguments.
+// fieldType returns the type of the data field by path, or of the value set by a computed setter.
+func (vm *ViewModel) fieldType(path string) (reflect.Type, error) {
+	if setter, ok := vm.setter(path); ok {
+		return setter.Type().In(1), nil
+	}
+	value, err := vm.field(path)
+	if err != nil {
+		return nil, err
+	}
+	return value.Type(), nil
+}
+
+// field returns the data field by path, e.g. User.Name.
+func (vm *ViewModel) field(path string) (reflect.Value, error) {
+	value := reflect.Indirect(vm.data)
+	for _, name := range strings.Split(path, ".") {
+		value = reflect.Indirect(value.FieldByName(name))
+		if !value.IsValid() {
+			return value, fmt.Errorf("unknown data field: %s", path)
+		}
+	}
+	return value, nil
+}
+
+// Go asynchronously calls the given method with optional arguments on a goroutine.
 // Blocking functions must be called asynchronously.
+// The method receives a context which posts changes to the update loop, see Update.
+// The component is rendered once the method returns.
 func (vm *ViewModel) Go(method string, args ...interface{}) {
-	values := make([]reflect.Value, 0, len(args))
-	for _, arg := range args {
-		values = append(values, reflect.ValueOf(arg))
-	}
-	go vm.call(method, values)
+	go func() {
+		err := vm.invokeAsync(method, args)
+		vm.Update(func(Context) {
+			if err != nil {
+				vm.handleError(vm.newError(err, "", "method "+method))
+			}
+		})
+	}()
+}
+
+// Update calls the function with the context of the update loop, then renders the component.
+// Data is required to be accessed on the update loop, e.g. by goroutines after fetching data.
+// For example: vctx.Update(func(vctx vue.Context) { vctx.Set("Answer", answer) })
+func (vm *ViewModel) Update(function func(Context)) {
+	updates.post(func() {
+		defer vm.catch("update")
+		function(vm)
+		vm.mutated()
+	})
 }
 
 // Emit dispatches the given event with optional arguments.
+// The input and update events of a subcomponent bound by the vue model attribute update the parent field
+// without propagating to the ancestors.
+// Events listened to by the vue on attribute of the subcomponent element are handled by the parent,
+// otherwise the events propagate to the subscribed ancestors.
+// For example: vctx.Emit("input", value)
 func (vm *ViewModel) Emit(event string, args ...interface{}) {
-	vm.bus.pub(event, "", args)
+	if _, ok := vm.comp.emits[event]; !ok && len(vm.comp.emits) > 0 {
+		vm.handleError(vm.newError(fmt.Errorf("undeclared event: %s", event), "", "emit "+event))
+	}
+	updated := false
+	if vm.model != nil && len(args) > 0 && (event == "input" || event == "update") {
+		vm.model.update(args[0])
+		updated = true
+	}
+	if handlers, ok := vm.listeners[event]; ok {
+		for _, handler := range handlers {
+			vm.parent.emitted(event, handler, args)
+		}
+		return
+	}
+	if !updated {
+		vm.bus.pub(event, "", args)
+	}
 }
 
-// call calls the given method with optional values then calls render.
-func (vm *ViewModel) call(method string, values []reflect.Value) {
-	if function, ok := vm.comp.methods[method]; ok {
-		values = append([]reflect.Value{reflect.ValueOf(vm)}, values...)
-		function.Call(values)
-		vm.render()
+// NextTick calls the function after the next update is rendered into the document.
+// For example, elements may be accessed after setting the fields which render them.
+func (vm *ViewModel) NextTick(function func()) {
+	updates.nextTick(func() {
+		defer vm.catch("next tick")
+		function()
+	})
+}
+
+// call calls the given method with optional arguments then schedules a render.
+// Errors of the method, e.g. panics, are handled without rendering.
+func (vm *ViewModel) call(method string, args []interface{}) {
+	defer vm.catch("method " + method)
+	if _, ok := vm.comp.methods[method]; !ok {
+		return
+	}
+	if err := vm.invoke(vm, method, args); err != nil {
+		vm.handleError(vm.newError(err, "", "method "+method))
+		return
+	}
+	vm.mutated()
+}
+
+// invoke calls the method with the context and optional arguments.
+// Arguments are converted into the parameter types of the method.
+func (vm *ViewModel) invoke(vctx Context, method string, args []interface{}) error {
+	function, ok := vm.comp.methods[method]
+	if !ok {
+		return nil
+	}
+	values, err := convertArgs(function.Type(), 1, args)
+	if err != nil {
+		return err
 	}
+	values = append([]reflect.Value{reflect.ValueOf(vctx)}, values...)
+	function.Call(values)
+	return nil
 }
 
-// compute calls the given function and returns the first element.
-func (vm *ViewModel) compute(function reflect.Value) interface{} {
-	values := []reflect.Value{reflect.ValueOf(vm)}
-	rets := function.Call(values)
-	return rets[0].Interface()
+// invokeAsync calls the method with an async context and optional arguments.
+// Panics of the method are returned as errors.
+func (vm *ViewModel) invokeAsync(method string, args []interface{}) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recovered(r)
+		}
+	}()
+	return vm.invoke(asyncContext{vm: vm}, method, args)
 }
@@ -0,0 +1,188 @@
+package vue
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeLayouts are the layouts of the time input types.
+var timeLayouts = map[string]string{
+	"date":           "2006-01-02",
+	"datetime-local": "2006-01-02T15:04",
+	"month":          "2006-01",
+	"time":           "15:04",
+}
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// convertArgs converts the arguments into the parameter types of the function type.
+// Parameters before the offset are skipped, e.g. the context.
+func convertArgs(typ reflect.Type, offset int, args []interface{}) ([]reflect.Value, error) {
+	n := typ.NumIn() - offset
+	if typ.IsVariadic() && len(args) < n-1 || !typ.IsVariadic() && len(args) != n {
+		return nil, fmt.Errorf("invalid number of arguments: %d for function type: %s", len(args), typ)
+	}
+
+	values := make([]reflect.Value, 0, len(args))
+	for i, arg := range args {
+		var paramTyp reflect.Type
+		if j := offset + i; typ.IsVariadic() && j >= typ.NumIn()-1 {
+			paramTyp = typ.In(typ.NumIn() - 1).Elem()
+		} else {
+			paramTyp = typ.In(j)
+		}
+		value, err := convertValue(arg, paramTyp)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// convertValue converts the value into the type.
+// Numbers are converted between kinds, nil is converted into the zero value.
+func convertValue(value interface{}, typ reflect.Type) (reflect.Value, error) {
+	if value == nil {
+		return reflect.Zero(typ), nil
+	}
+	val := reflect.ValueOf(value)
+	if val.Type().AssignableTo(typ) {
+		return val, nil
+	}
+	if isNumber(val.Kind()) && isNumber(typ.Kind()) || val.Kind() == typ.Kind() && val.Type().ConvertibleTo(typ) {
+		return val.Convert(typ), nil
+	}
+	return reflect.Value{}, fmt.Errorf("cannot convert %T to type: %s", value, typ)
+}
+
+// isNumber returns true if the kind is an integer or float.
+func isNumber(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// convertModel converts the value of a form element into the type of the field with the modifiers.
+// For example: .trim trims strings and .number parses numbers for fields of any type.
+func convertModel(value interface{}, typ reflect.Type, modSet map[string]struct{}) (reflect.Value, error) {
+	switch value := value.(type) {
+	case string:
+		if _, ok := modSet["trim"]; ok {
+			value = strings.TrimSpace(value)
+		}
+		if _, ok := modSet["number"]; ok && typ.Kind() == reflect.Interface {
+			if f, err := strconv.ParseFloat(value, 64); err == nil {
+				return reflect.ValueOf(f), nil
+			}
+		}
+		return parseValue(value, typ)
+	case []string:
+		if typ.Kind() != reflect.Slice || typ.Elem().Kind() == reflect.String {
+			return convertValue(value, typ)
+		}
+		values := reflect.MakeSlice(typ, 0, len(value))
+		for _, val := range value {
+			elem, err := parseValue(val, typ.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			values = reflect.Append(values, elem)
+		}
+		return values, nil
+	default:
+		return convertValue(value, typ)
+	}
+}
+
+// parseValue parses the string into the type, e.g. the input of a form element.
+// Strings, bools, numbers, times and text unmarshalers are supported.
+// An empty string parses into the zero value.
+func parseValue(s string, typ reflect.Type) (reflect.Value, error) {
+	if typ.Kind() == reflect.Ptr {
+		elem, err := parseValue(s, typ.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		ptr := reflect.New(typ.Elem())
+		ptr.Elem().Set(elem)
+		return ptr, nil
+	}
+
+	value := reflect.New(typ).Elem()
+	if s == "" && typ.Kind() != reflect.String {
+		return value, nil
+	}
+
+	if typ == timeType {
+		for _, layout := range timeLayouts {
+			if t, err := time.Parse(layout, s); err == nil {
+				return reflect.ValueOf(t), nil
+			}
+		}
+	}
+	if reflect.PtrTo(typ).Implements(textUnmarshalerType) {
+		err := value.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+		return value, err
+	}
+
+	switch typ.Kind() {
+	case reflect.String, reflect.Interface:
+		value.Set(reflect.ValueOf(s))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, typ.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, typ.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, typ.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value.SetFloat(f)
+	default:
+		return reflect.Value{}, fmt.Errorf("cannot parse %q into type: %s", s, typ)
+	}
+	return value, nil
+}
+
+// formatValue formats the value for the input type of a form element.
+// Times are formatted by the layout of the input type.
+func formatValue(value interface{}, input string) string {
+	switch value := value.(type) {
+	case time.Time:
+		if layout, ok := timeLayouts[input]; ok {
+			return value.Format(layout)
+		}
+	case encoding.TextMarshaler:
+		if text, err := value.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+	return fmt.Sprintf("%v", value)
+}
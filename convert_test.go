@@ -0,0 +1,113 @@
+//go:build js && wasm
+
+package vue
+
+import (
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseValue(t *testing.T) {
+	ten := 10
+	tests := []struct {
+		s    string
+		typ  reflect.Type
+		want interface{}
+	}{
+		{"text", reflect.TypeOf(""), "text"},
+		{"", reflect.TypeOf(""), ""},
+		{"true", reflect.TypeOf(false), true},
+		{"", reflect.TypeOf(false), false},
+		{"-42", reflect.TypeOf(0), -42},
+		{"", reflect.TypeOf(0), 0},
+		{"127", reflect.TypeOf(int8(0)), int8(127)},
+		{"42", reflect.TypeOf(uint(0)), uint(42)},
+		{"1.5", reflect.TypeOf(0.0), 1.5},
+		{"1.5", reflect.TypeOf(float32(0)), float32(1.5)},
+		{"10", reflect.TypeOf(&ten), &ten},
+		{"any", reflect.TypeOf((*interface{})(nil)).Elem(), "any"},
+		{"2020-01-02", timeType, time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"15:04", timeType, time.Date(0, 1, 1, 15, 4, 0, 0, time.UTC)},
+		{"127.0.0.1", reflect.TypeOf(net.IP{}), net.ParseIP("127.0.0.1")},
+	}
+	for _, test := range tests {
+		got, err := parseValue(test.s, test.typ)
+		if err != nil {
+			t.Errorf("parseValue(%q, %s) error: %v", test.s, test.typ, err)
+			continue
+		}
+		if !reflect.DeepEqual(got.Interface(), test.want) {
+			t.Errorf("parseValue(%q, %s) = %#v, want %#v", test.s, test.typ, got.Interface(), test.want)
+		}
+	}
+}
+
+func TestParseValueError(t *testing.T) {
+	tests := []struct {
+		s   string
+		typ reflect.Type
+	}{
+		{"yes", reflect.TypeOf(false)},
+		{"1.5", reflect.TypeOf(0)},
+		{"128", reflect.TypeOf(int8(0))},
+		{"-1", reflect.TypeOf(uint(0))},
+		{"abc", reflect.TypeOf(0.0)},
+		{"abc", reflect.TypeOf(struct{}{})},
+		{"abc", reflect.TypeOf([]int{})},
+	}
+	for _, test := range tests {
+		if got, err := parseValue(test.s, test.typ); err == nil {
+			t.Errorf("parseValue(%q, %s) = %#v, want error", test.s, test.typ, got)
+		}
+	}
+}
+
+func TestConvertModel(t *testing.T) {
+	tests := []struct {
+		value  interface{}
+		typ    reflect.Type
+		modSet map[string]struct{}
+		want   interface{}
+	}{
+		{"text", reflect.TypeOf(""), nil, "text"},
+		{"  text  ", reflect.TypeOf(""), map[string]struct{}{"trim": {}}, "text"},
+		{" 42 ", reflect.TypeOf(0), map[string]struct{}{"trim": {}}, 42},
+		{"42", reflect.TypeOf(0), nil, 42},
+		{"1.5", reflect.TypeOf((*interface{})(nil)).Elem(), map[string]struct{}{"number": {}}, 1.5},
+		{"abc", reflect.TypeOf((*interface{})(nil)).Elem(), map[string]struct{}{"number": {}}, "abc"},
+		{"abc", reflect.TypeOf((*interface{})(nil)).Elem(), nil, "abc"},
+		{true, reflect.TypeOf(false), nil, true},
+		{[]string{"a", "b"}, reflect.TypeOf([]string{}), nil, []string{"a", "b"}},
+		{[]string{"1", "2"}, reflect.TypeOf([]int{}), nil, []int{1, 2}},
+		{[]string{}, reflect.TypeOf([]int{}), nil, []int{}},
+	}
+	for _, test := range tests {
+		got, err := convertModel(test.value, test.typ, test.modSet)
+		if err != nil {
+			t.Errorf("convertModel(%#v, %s) error: %v", test.value, test.typ, err)
+			continue
+		}
+		if !reflect.DeepEqual(got.Interface(), test.want) {
+			t.Errorf("convertModel(%#v, %s) = %#v, want %#v", test.value, test.typ, got.Interface(), test.want)
+		}
+	}
+}
+
+func TestConvertModelError(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		typ   reflect.Type
+	}{
+		{"abc", reflect.TypeOf(0)},
+		{[]string{"1", "x"}, reflect.TypeOf([]int{})},
+		{true, reflect.TypeOf("")},
+		{[]string{"a"}, reflect.TypeOf("")},
+	}
+	for _, test := range tests {
+		if got, err := convertModel(test.value, test.typ, nil); err == nil {
+			t.Errorf("convertModel(%#v, %s) = %#v, want error", test.value, test.typ, got.Interface())
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package vue
+
+import (
+	"errors"
+	"fmt"
+	"golang.org/x/net/html"
+	"strings"
+)
+
+// Error is an error of a component, e.g. from the template, a directive, a method or a watcher.
+type Error struct {
+	// Component is the name of the component, e.g. the element of a subcomponent.
+	Component string
+	// Location is the path of elements in the template, e.g. ul > li.
+	Location string
+	// Directive is the failed directive, e.g. v-if="Done" or method Remove.
+	Directive string
+	// Err is the underlying error.
+	Err error
+}
+
+// Error returns the message with the component, location and directive.
+func (err *Error) Error() string {
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "vue: component %s", err.Component)
+	if err.Location != "" {
+		fmt.Fprintf(sb, " at %s", err.Location)
+	}
+	if err.Directive != "" {
+		fmt.Fprintf(sb, " in %s", err.Directive)
+	}
+	fmt.Fprintf(sb, ": %v", err.Err)
+	return sb.String()
+}
+
+// Unwrap returns the underlying error.
+func (err *Error) Unwrap() error {
+	return err.Err
+}
+
+// errorHandler is the global error handler.
+var errorHandler func(err *Error)
+
+// SetErrorHandler sets the global error handler.
+// The handler receives errors which are not handled by components.
+// Without a handler, errors are reported to the browser console.
+func SetErrorHandler(handler func(err *Error)) {
+	errorHandler = handler
+}
+
+// newError creates an error of the component with the directive at the location.
+// Errors of the component are returned as is, e.g. from nested elements.
+func (vm *ViewModel) newError(err error, location, directive string) *Error {
+	var vErr *Error
+	if errors.As(err, &vErr) && vErr.Component == vm.comp.name {
+		return vErr
+	}
+	if vErr != nil && vErr.Component == "" {
+		// Errors at component creation are named once the component is registered.
+		// The error is copied since it may be shared by instances, e.g. compile errors.
+		named := *vErr
+		named.Component = vm.comp.name
+		return &named
+	}
+	return &Error{
+		Component: vm.comp.name,
+		Location:  location,
+		Directive: directive,
+		Err:       err,
+	}
+}
+
+// handleError delivers the error to the error captured hooks of the ancestors
+// then to the nearest error handler of the components, the global error handler or the console.
+// An error captured hook returning false stops the error from propagating.
+func (vm *ViewModel) handleError(err error) {
+	vErr := vm.newError(err, "", "")
+	for parent := vm.parent; parent != nil; parent = parent.parent {
+		for _, function := range parent.comp.errorCaptured {
+			if !function(parent, vErr) {
+				return
+			}
+		}
+	}
+
+	for owner := vm; owner != nil; owner = owner.parent {
+		if owner.comp.errorHandler != nil {
+			owner.comp.errorHandler(owner, vErr)
+			return
+		}
+	}
+
+	if errorHandler != nil {
+		errorHandler(vErr)
+		return
+	}
+	warn(vErr)
+}
+
+// catch recovers a panic of the directive as an error to be handled, e.g. from a method.
+// The function is required to be deferred.
+func (vm *ViewModel) catch(directive string) {
+	if r := recover(); r != nil {
+		vm.handleError(vm.newError(recovered(r), "", directive))
+	}
+}
+
+// recovered returns the recovered value of a panic as an error.
+func recovered(r interface{}) error {
+	if err, ok := r.(error); ok {
+		return err
+	}
+	return fmt.Errorf("%v", r)
+}
+
+// nodeLocation returns the path of elements from the template root to the html node.
+// For example: ul > li
+func nodeLocation(node *html.Node) string {
+	var elements []string
+	for ; node != nil; node = node.Parent {
+		if node.Type == html.ElementNode && node.Data != "" {
+			elements = append([]string{node.Data}, elements...)
+		}
+	}
+	return strings.Join(elements, " > ")
+}
+
+// elementLocation returns the path of elements from the template root to the element in the html node.
+func elementLocation(parent *html.Node, element string) string {
+	if location := nodeLocation(parent); location != "" {
+		return location + " > " + element
+	}
+	return element
+}
@@ -1,102 +1,424 @@
 package vue
 
 import (
+	"fmt"
 	"github.com/gowasm/go-js-dom"
+	"strconv"
 	"strings"
 	"syscall/js"
 )
 
+// eventArg is the name of the event argument of handlers, e.g. Update($event).
+const eventArg = "$event"
+
+// modelAttr is the attribute prefix of the vue model binding, e.g. model.input.
+const modelAttr = "model."
+
 // keyboardEvent is the keyboard event type.
 var keyboardEvent = js.Global().Get("KeyboardEvent")
 
-// addEventListener adds the callback to the element as an event listener unless the type was previously added.
-func (vm *ViewModel) addEventListener(typ string, cb func(dom.Event)) {
-	if _, ok := vm.funcs[typ]; ok {
+// mouseEvent is the mouse event type.
+var mouseEvent = js.Global().Get("MouseEvent")
+
+// eventMods are the standard event modifiers, other modifiers filter keys.
+var eventMods = map[string]struct{}{
+	"prevent": {},
+	"stop":    {},
+	"once":    {},
+	"self":    {},
+	"capture": {},
+	"passive": {},
+	"exact":   {},
+}
+
+// sysMods maps the system modifiers to the properties of mouse and keyboard events.
+var sysMods = map[string]string{
+	"ctrl":  "ctrlKey",
+	"alt":   "altKey",
+	"shift": "shiftKey",
+	"meta":  "metaKey",
+}
+
+// buttonMods maps the mouse button modifiers to the buttons of mouse events.
+var buttonMods = map[string]int{
+	"left":   0,
+	"middle": 1,
+	"right":  2,
+}
+
+// keyAliases maps the key modifiers to keys which differ from the title conversion.
+var keyAliases = map[string]string{
+	"esc":   "Escape",
+	"space": " ",
+	"up":    "ArrowUp",
+	"down":  "ArrowDown",
+	"left":  "ArrowLeft",
+	"right": "ArrowRight",
+	"del":   "Delete",
+}
+
+// Event is the dom event received by methods from the vue on attribute.
+// For example: v-on:input="Update($event)" calls func(vctx vue.Context, event vue.Event)
+type Event struct {
+	dom.Event
+}
+
+// Value returns the value of the event target, e.g. an input.
+func (event Event) Value() string {
+	return event.Target().Underlying().Get("value").String()
+}
+
+// Checked returns true if the event target is checked, e.g. a checkbox.
+func (event Event) Checked() bool {
+	return event.Target().Underlying().Get("checked").Bool()
+}
+
+// Key returns the key of a keyboard event.
+func (event Event) Key() string {
+	if !event.Underlying().InstanceOf(keyboardEvent) {
+		return ""
+	}
+	return event.Underlying().Get("key").String()
+}
+
+// handler is a method bound to an element by the vue on attribute, or the model of the vue model attribute.
+// The arguments are evaluated in the scope of the element when the event is dispatched.
+type handler struct {
+	method string
+	args   []expr
+	scope  *scope
+	dir    *directive
+	model  *model
+}
+
+// onceKey identifies a handler of the once modifier across renders
+// by the directive and the iteration of its scope.
+type onceKey struct {
+	dir  *directive
+	path string
+}
+
+// listener identifies an event listener of the root element.
+// Listeners of the vue model and on attributes are added separately for the same event type.
+type listener struct {
+	typ              string
+	capture, passive bool
+	model            bool
+}
+
+// addEventListener adds the callback to the element as an event listener unless the listener was previously added.
+func (vm *ViewModel) addEventListener(listener listener, cb func(dom.Event, listener)) {
+	if _, ok := vm.funcs[listener]; ok {
 		return
 	}
-	fn := vm.vnode.node.AddEventListener(typ, cb, false)
-	vm.funcs[typ] = fn
+	fn := js.FuncOf(func(_ js.Value, args []js.Value) interface{} {
+		defer vm.catch(listener.typ + " event")
+		cb(dom.WrapEvent(args[0]), listener)
+		return nil
+	})
+	options := map[string]interface{}{"capture": listener.capture, "passive": listener.passive}
+	vm.vnode.node.Underlying().Call("addEventListener", listener.typ, fn, options)
+	vm.funcs[listener] = fn
 }
 
 // vModel is the vue model event callback.
-func (vm *ViewModel) vModel(event dom.Event) {
-	event.StopImmediatePropagation()
-
+// The field path of the model is resolved in the scope of the element, e.g. loop variables.
+func (vm *ViewModel) vModel(event dom.Event, _ listener) {
 	target := event.Target()
-	_, field, ok := findAttr(target, event.Type())
+	ref, _, ok := modelAttribute(target, event.Type())
+	if !ok {
+		return
+	}
+	id, ok := vm.owns(ref)
 	if !ok {
 		return
 	}
+	handler, ok := vm.handler(id)
+	if !ok || handler.model == nil {
+		return
+	}
 
-	value := target.Underlying().Get("value").String()
-	vm.Set(field, value)
-	vm.render()
+	model := handler.model
+	current, err := model.expr.eval(vm, model.scope)
+	if err != nil {
+		vm.handleError(vm.newError(err, "", "v-model "+model.field))
+		return
+	}
+	model.update(modelValue(target, current))
 }
 
 // vOn is the vue on event callback.
-func (vm *ViewModel) vOn(event dom.Event) {
-	event.StopImmediatePropagation()
-
-	typ := event.Type()
-	attrKey, method, ok := findAttr(event.Target(), typ)
-	if !ok {
-		return
+// Handlers are dispatched along the path from the target to the root element, or reversed for capture.
+func (vm *ViewModel) vOn(event dom.Event, listener listener) {
+	path := eventPath(event)
+	if listener.capture {
+		for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+			path[i], path[j] = path[j], path[i]
+		}
 	}
 
-	modifiers := strings.TrimPrefix(attrKey, typ)
-	modSet := modSet(modifiers)
+	for _, elem := range path {
+		stop := false
+		for attrKey, attrVal := range elem.Attributes() {
+			mods := strings.Split(attrKey, ".")
+			if mods[0] != event.Type() {
+				continue
+			}
+			modSet := modSet(mods[1:])
+			if !matchListener(modSet, listener) || !matchEvent(modSet, event, elem) {
+				continue
+			}
+			id, ok := vm.owns(attrVal)
+			if !ok {
+				continue
+			}
+			handler, ok := vm.handler(id)
+			if !ok {
+				continue
+			}
+			if _, ok := modSet["once"]; ok {
+				key := onceKey{dir: handler.dir, path: handler.scope.path}
+				if _, ok := vm.fired[key]; ok {
+					continue
+				}
+				vm.fired[key] = struct{}{}
+			}
 
-	if event.Underlying().InstanceOf(keyboardEvent) {
-		keyType := event.Underlying().Get("key").String()
-		if _, ok := modSet[keyType]; !ok && len(modSet) > 0 {
+			if _, ok := modSet["prevent"]; ok {
+				event.PreventDefault()
+			}
+			if _, ok := modSet["stop"]; ok {
+				event.StopPropagation()
+				stop = true
+			}
+			args, err := handler.eval(vm, event)
+			if err != nil {
+				vm.handleError(vm.newError(err, "", "v-on:"+attrKey))
+				continue
+			}
+			vm.bus.pub(event.Type(), handler.method, args)
+		}
+		if stop {
 			return
 		}
 	}
-
-	vm.bus.pub(typ, method, nil)
 }
 
 // release removes all the event listeners.
 func (vm *ViewModel) release() {
-	for typ, fn := range vm.funcs {
-		vm.vnode.node.RemoveEventListener(typ, fn, false)
+	for listener, fn := range vm.funcs {
+		options := map[string]interface{}{"capture": listener.capture}
+		vm.vnode.node.Underlying().Call("removeEventListener", listener.typ, fn, options)
+		fn.Release()
+	}
+}
+
+// ref references the value as owned by the view model, e.g. for attributes.
+// For example: 3 -> "1:3"
+func (vm *ViewModel) ref(value interface{}) string {
+	return fmt.Sprintf("%d:%v", vm.id, value)
+}
+
+// owns returns the value of the reference if it is owned by the view model.
+// Returns false if the reference belongs to another view model, e.g. a subcomponent.
+func (vm *ViewModel) owns(ref string) (string, bool) {
+	vals := strings.SplitN(ref, ":", 2)
+	if len(vals) != 2 || vals[0] != strconv.Itoa(vm.id) {
+		return "", false
+	}
+	return vals[1], true
+}
+
+// handler finds the handler by id from the last render.
+// Returns false if the handler is not found.
+func (vm *ViewModel) handler(id string) (*handler, bool) {
+	i, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, false
 	}
+	handler, ok := vm.handlers[i]
+	return handler, ok
 }
 
-// findAttr finds the attribute from the given prefix by searching up the dom tree.
-func findAttr(elem dom.Element, prefix string) (string, string, bool) {
-	if elem == nil {
-		return "", "", false
+// eval evaluates the arguments of the handler with the event declared as $event.
+// A method name without a call receives the event if the method accepts an argument.
+func (handler *handler) eval(vm *ViewModel, event dom.Event) ([]interface{}, error) {
+	arg := Event{Event: event}
+	if handler.args == nil {
+		if function, ok := vm.comp.methods[handler.method]; ok && function.Type().NumIn() == 2 {
+			return []interface{}{arg}, nil
+		}
+		return nil, nil
 	}
-	for attrKey, attrVal := range elem.Attributes() {
-		if strings.HasPrefix(attrKey, prefix) {
-			return attrKey, attrVal, true
+
+	scope := handler.scope.child(map[string]interface{}{eventArg: arg})
+	args := make([]interface{}, 0, len(handler.args))
+	for _, x := range handler.args {
+		value, err := x.eval(vm, scope)
+		if err != nil {
+			return nil, err
 		}
+		args = append(args, value)
 	}
-	return findAttr(elem.ParentElement(), prefix)
+	return args, nil
 }
 
-// modSet converts modifiers to a set, includes title conversion.
-// For example: hello.world -> {"Hello", "World"}
-func modSet(modifiers string) map[string]struct{} {
-	if modifiers == "" {
-		return nil
+// emitted calls the handler with the arguments of the event emitted by a subcomponent.
+// A method name without a call receives the arguments, otherwise the first argument is declared as $event.
+// For example: v-on:remove="Remove(Index, $event)"
+func (vm *ViewModel) emitted(event string, handler *handler, args []interface{}) {
+	if handler.args == nil {
+		vm.call(handler.method, args)
+		return
+	}
+
+	var arg interface{}
+	if len(args) > 0 {
+		arg = args[0]
+	}
+	scope := handler.scope.child(map[string]interface{}{eventArg: arg})
+	values := make([]interface{}, 0, len(handler.args))
+	for _, x := range handler.args {
+		value, err := x.eval(vm, scope)
+		if err != nil {
+			vm.handleError(vm.newError(err, "", "v-on:"+event))
+			return
+		}
+		values = append(values, value)
+	}
+	vm.call(handler.method, values)
+}
+
+// nativeEvent removes the native modifier from the event type.
+// Returns true if the event is native, e.g. click.native on a subcomponent element.
+func nativeEvent(typ string) (string, bool) {
+	mods := strings.Split(typ, ".")
+	for i := 1; i < len(mods); i++ {
+		if mods[i] == "native" {
+			return strings.Join(append(mods[:i], mods[i+1:]...), "."), true
+		}
+	}
+	return typ, false
+}
+
+// handlerMethod returns the method name and arguments of the vue on expression.
+// For example: Remove(Item) -> "Remove", [Item]
+// Returns false if the expression is not a method name nor a method call.
+func handlerMethod(x expr) (string, []expr, bool) {
+	switch x := x.(type) {
+	case *identExpr:
+		return x.name, nil, true
+	case *callExpr:
+		if fn, ok := x.fn.(*identExpr); ok {
+			return fn.name, x.args, true
+		}
 	}
-	mods := strings.Split(modifiers, ".")
+	return "", nil, false
+}
+
+// eventListener returns the listener of the event type with modifiers.
+// For example: click.capture.once -> {click, true, false}
+func eventListener(typ string) listener {
+	mods := strings.Split(typ, ".")
+	modSet := modSet(mods[1:])
+	_, capture := modSet["capture"]
+	_, passive := modSet["passive"]
+	return listener{typ: mods[0], capture: capture, passive: passive}
+}
+
+// matchListener returns true if the modifiers match the capture and passive options of the listener.
+func matchListener(modSet map[string]struct{}, listener listener) bool {
+	_, capture := modSet["capture"]
+	_, passive := modSet["passive"]
+	return capture == listener.capture && passive == listener.passive
+}
+
+// matchEvent returns true if the event matches the self, system, button and key modifiers.
+func matchEvent(modSet map[string]struct{}, event dom.Event, elem dom.Element) bool {
+	if _, ok := modSet["self"]; ok && !event.Target().Underlying().Equal(elem.Underlying()) {
+		return false
+	}
+	if !matchSystem(modSet, event) {
+		return false
+	}
+
+	switch value := event.Underlying(); {
+	case value.InstanceOf(mouseEvent):
+		button := value.Get("button").Int()
+		for mod, modButton := range buttonMods {
+			if _, ok := modSet[mod]; ok && button != modButton {
+				return false
+			}
+		}
+	case value.InstanceOf(keyboardEvent):
+		return matchKey(modSet, value.Get("key").String())
+	}
+	return true
+}
+
+// matchSystem returns true if the system keys of the event are pressed for the system modifiers.
+// With the exact modifier, other system keys must not be pressed.
+func matchSystem(modSet map[string]struct{}, event dom.Event) bool {
+	_, exact := modSet["exact"]
+	for mod, prop := range sysMods {
+		_, ok := modSet[mod]
+		pressed := event.Underlying().Get(prop).Truthy()
+		if ok && !pressed || exact && !ok && pressed {
+			return false
+		}
+	}
+	return true
+}
+
+// matchKey returns true if the key matches a key modifier or there are no key modifiers.
+// For example: page-down matches PageDown, s matches s or S and esc matches Escape.
+func matchKey(modSet map[string]struct{}, key string) bool {
+	hasKeys := false
+	for mod := range modSet {
+		if _, ok := eventMods[mod]; ok {
+			continue
+		}
+		if _, ok := sysMods[mod]; ok {
+			continue
+		}
+		hasKeys = true
+		if alias, ok := keyAliases[mod]; ok && alias == key || kebabTitle(mod) == key || strings.EqualFold(mod, key) {
+			return true
+		}
+	}
+	return !hasKeys
+}
+
+// eventPath returns the elements from the target of the event up to the root element.
+func eventPath(event dom.Event) []dom.Element {
+	root := event.CurrentTarget()
+	var path []dom.Element
+	for elem := event.Target(); elem != nil; elem = elem.ParentElement() {
+		path = append(path, elem)
+		if elem.Underlying().Equal(root.Underlying()) {
+			break
+		}
+	}
+	return path
+}
+
+// modSet converts modifiers to a set.
+// For example: [prevent, page-down] -> {"prevent", "page-down"}
+func modSet(mods []string) map[string]struct{} {
 	set := make(map[string]struct{}, len(mods))
 	for _, mod := range mods {
-		set[modTitle(mod)] = struct{}{}
+		set[mod] = struct{}{}
 	}
 	return set
 }
 
-// modTitle converts modifiers to title style.
+// kebabTitle converts kebab case to title style, e.g. modifiers and attributes.
 // For example: page-down -> PageDown
-func modTitle(modifier string) string {
-	mods := strings.Split(modifier, "-")
+func kebabTitle(kebab string) string {
+	words := strings.Split(kebab, "-")
 	sb := &strings.Builder{}
-	for _, mod := range mods {
-		sb.WriteString(strings.Title(mod))
+	for _, word := range words {
+		sb.WriteString(strings.Title(word))
 	}
 	return sb.String()
 }
@@ -42,7 +42,7 @@ func main() {
 	)
 
 	time.AfterFunc(time.Second, func() {
-		vm.Go("Add")
+		vm.Update(Add)
 	})
 	select {}
 }
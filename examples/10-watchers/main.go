@@ -36,22 +36,25 @@ func Answer(vctx vue.Context, newQuestion, _ string) {
 }
 
 func AsyncAnswer(vctx vue.Context) {
-	data := vctx.Data().(*Data)
+	answer := fetchAnswer()
+	vctx.Update(func(vctx vue.Context) {
+		vctx.Set("Answer", answer)
+	})
+}
+
+func fetchAnswer() string {
 	res, err := http.Get("https://yesno.wtf/api")
 	if err != nil {
-		data.Answer = err.Error()
-		return
+		return err.Error()
 	}
 	defer res.Body.Close()
 
 	dec := json.NewDecoder(res.Body)
 	yesno := &yesno{}
-	err = dec.Decode(yesno)
-	if err != nil {
-		data.Answer = err.Error()
-		return
+	if err := dec.Decode(yesno); err != nil {
+		return err.Error()
 	}
-	data.Answer = yesno.Answer
+	return yesno.Answer
 }
 
 func main() {
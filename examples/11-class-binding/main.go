@@ -38,7 +38,7 @@ func main() {
 	for tick := time.Tick(time.Second); ; {
 		select {
 		case <-tick:
-			vm.Go("Change")
+			vm.Update(Change)
 		}
 	}
 }
@@ -53,7 +53,7 @@ func main() {
 	for tick := time.Tick(200 * time.Millisecond); ; {
 		select {
 		case <-tick:
-			vm.Go("Change")
+			vm.Update(Change)
 		}
 	}
 }
@@ -0,0 +1,694 @@
+package vue
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// expr is a parsed expression of a directive value.
+// For example: !Done, Count > 0 && Ready, '/user/' + Id or User.Avatar
+type expr interface {
+	eval(vm *ViewModel, scope *scope) (interface{}, error)
+}
+
+type (
+	// literalExpr is a literal value, e.g. 'text', 10, 1.5, true or nil.
+	literalExpr struct {
+		value interface{}
+	}
+
+	// identExpr is a field of the scope, e.g. Items.
+	identExpr struct {
+		name string
+	}
+
+	// memberExpr is a field of a value, e.g. User.Avatar.
+	memberExpr struct {
+		x    expr
+		name string
+	}
+
+	// indexExpr is an element of a value, e.g. Items[0] or Labels['name'].
+	indexExpr struct {
+		x, index expr
+	}
+
+	// callExpr is a call of a method, e.g. Format(Date) or User.FullName().
+	// Methods of the component are called with the context.
+	callExpr struct {
+		fn   expr
+		args []expr
+	}
+
+	// unaryExpr is a unary operation, e.g. !Done or -Count.
+	unaryExpr struct {
+		op string
+		x  expr
+	}
+
+	// binaryExpr is a binary operation, e.g. Count > 0.
+	binaryExpr struct {
+		op   string
+		x, y expr
+	}
+)
+
+// precedences are the precedences of binary operators, higher binds tighter.
+var precedences = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "!=": 3,
+	"<": 4, "<=": 4, ">": 4, ">=": 4,
+	"+": 5, "-": 5,
+	"*": 6, "/": 6, "%": 6,
+}
+
+// tokenKind is the kind of a token.
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenOp
+)
+
+// token is a lexical token of an expression.
+type token struct {
+	kind tokenKind
+	val  string
+	pos  int
+}
+
+// parser parses tokens into an expression.
+type parser struct {
+	src    string
+	tokens []token
+	pos    int
+}
+
+// parseExpr parses the source into an expression.
+func parseExpr(src string) (expr, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{src: src, tokens: tokens}
+	x, err := p.parseBinary(1)
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, p.errorf(tok, "unexpected %q", tok.val)
+	}
+	return x, nil
+}
+
+// lex splits the source into tokens.
+func lex(src string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case isIdentStart(c):
+			j := i + 1
+			for j < len(src) && (isIdentStart(src[j]) || isDigit(src[j])) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, val: src[i:j], pos: i})
+			i = j
+		case isDigit(c):
+			j := i + 1
+			for j < len(src) && (isDigit(src[j]) || src[j] == '.' && j+1 < len(src) && isDigit(src[j+1])) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, val: src[i:j], pos: i})
+			i = j
+		case c == '\'' || c == '"':
+			sb := &strings.Builder{}
+			j := i + 1
+			for ; j < len(src) && src[j] != c; j++ {
+				if src[j] == '\\' && j+1 < len(src) {
+					j++
+				}
+				sb.WriteByte(src[j])
+			}
+			if j == len(src) {
+				return nil, fmt.Errorf("unterminated string at %d in expression: %s", i, src)
+			}
+			tokens = append(tokens, token{kind: tokenString, val: sb.String(), pos: i})
+			i = j + 1
+		default:
+			if i+1 < len(src) {
+				if op := src[i : i+2]; precedences[op] != 0 {
+					tokens = append(tokens, token{kind: tokenOp, val: op, pos: i})
+					i += 2
+					continue
+				}
+			}
+			if !strings.ContainsRune("+-*/%<>!().,[]", rune(c)) {
+				return nil, fmt.Errorf("unexpected %q at %d in expression: %s", c, i, src)
+			}
+			tokens = append(tokens, token{kind: tokenOp, val: string(c), pos: i})
+			i++
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(src)}), nil
+}
+
+// parseBinary parses binary operations with at least the given precedence.
+func (p *parser) parseBinary(prec int) (expr, error) {
+	x, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		opPrec := precedences[tok.val]
+		if tok.kind != tokenOp || opPrec < prec {
+			return x, nil
+		}
+		p.next()
+		y, err := p.parseBinary(opPrec + 1)
+		if err != nil {
+			return nil, err
+		}
+		x = &binaryExpr{op: tok.val, x: x, y: y}
+	}
+}
+
+// parseUnary parses unary operations.
+func (p *parser) parseUnary() (expr, error) {
+	if tok := p.peek(); tok.kind == tokenOp && (tok.val == "!" || tok.val == "-") {
+		p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryExpr{op: tok.val, x: x}, nil
+	}
+	return p.parsePostfix()
+}
+
+// parsePostfix parses members, indexes and calls.
+func (p *parser) parsePostfix() (expr, error) {
+	x, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		if tok.kind != tokenOp {
+			return x, nil
+		}
+		switch tok.val {
+		case ".":
+			p.next()
+			name := p.next()
+			if name.kind != tokenIdent {
+				return nil, p.errorf(name, "expected field name")
+			}
+			x = &memberExpr{x: x, name: name.val}
+		case "[":
+			p.next()
+			index, err := p.parseBinary(1)
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			x = &indexExpr{x: x, index: index}
+		case "(":
+			p.next()
+			args, err := p.parseArgs()
+			if err != nil {
+				return nil, err
+			}
+			x = &callExpr{fn: x, args: args}
+		default:
+			return x, nil
+		}
+	}
+}
+
+// parseArgs parses the arguments of a call until the closing parenthesis.
+func (p *parser) parseArgs() ([]expr, error) {
+	args := make([]expr, 0)
+	if tok := p.peek(); tok.kind == tokenOp && tok.val == ")" {
+		p.next()
+		return args, nil
+	}
+	for {
+		arg, err := p.parseBinary(1)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+
+		tok := p.next()
+		if tok.kind == tokenOp && tok.val == ")" {
+			return args, nil
+		}
+		if tok.kind != tokenOp || tok.val != "," {
+			return nil, p.errorf(tok, "expected , or )")
+		}
+	}
+}
+
+// parsePrimary parses identifiers, literals and parentheses.
+func (p *parser) parsePrimary() (expr, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenIdent:
+		switch tok.val {
+		case "true":
+			return &literalExpr{value: true}, nil
+		case "false":
+			return &literalExpr{value: false}, nil
+		case "nil":
+			return &literalExpr{value: nil}, nil
+		}
+		return &identExpr{name: tok.val}, nil
+	case tokenNumber:
+		if strings.Contains(tok.val, ".") {
+			f, err := strconv.ParseFloat(tok.val, 64)
+			return &literalExpr{value: f}, err
+		}
+		i, err := strconv.Atoi(tok.val)
+		return &literalExpr{value: i}, err
+	case tokenString:
+		return &literalExpr{value: tok.val}, nil
+	case tokenOp:
+		if tok.val == "(" {
+			x, err := p.parseBinary(1)
+			if err != nil {
+				return nil, err
+			}
+			return x, p.expect(")")
+		}
+	}
+	if tok.kind == tokenEOF {
+		return nil, p.errorf(tok, "unexpected end")
+	}
+	return nil, p.errorf(tok, "unexpected %q", tok.val)
+}
+
+// peek returns the current token.
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+// next returns the current token and advances to the next token.
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// expect advances past the operator or returns an error.
+func (p *parser) expect(op string) error {
+	if tok := p.next(); tok.kind != tokenOp || tok.val != op {
+		return p.errorf(tok, "expected %s", op)
+	}
+	return nil
+}
+
+// errorf returns an error at the position of the token.
+func (p *parser) errorf(tok token, format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	return fmt.Errorf("%s at %d in expression: %s", msg, tok.pos, p.src)
+}
+
+// isIdentStart returns true if the character starts an identifier.
+func isIdentStart(c byte) bool {
+	return c == '_' || c == '$' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// isDigit returns true if the character is a decimal digit.
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// isPath returns true if the expression is a field path, e.g. User.Name.
+func isPath(x expr) bool {
+	switch x := x.(type) {
+	case *identExpr:
+		return true
+	case *memberExpr:
+		return isPath(x.x)
+	default:
+		return false
+	}
+}
+
+func (x *literalExpr) eval(vm *ViewModel, scope *scope) (interface{}, error) {
+	return x.value, nil
+}
+
+func (x *identExpr) eval(vm *ViewModel, scope *scope) (interface{}, error) {
+	vm.track(x.name)
+	value, ok := scope.get(x.name)
+	if !ok {
+		return nil, fmt.Errorf("unknown data field: %s", x.name)
+	}
+	return value, nil
+}
+
+func (x *memberExpr) eval(vm *ViewModel, scope *scope) (interface{}, error) {
+	value, err := x.x.eval(vm, scope)
+	if err != nil {
+		return nil, err
+	}
+	elem := indirect(reflect.ValueOf(value))
+	switch elem.Kind() {
+	case reflect.Struct:
+		if field := elem.FieldByName(x.name); field.IsValid() && field.CanInterface() {
+			return field.Interface(), nil
+		}
+	case reflect.Map:
+		if key := reflect.ValueOf(x.name); key.Type().ConvertibleTo(elem.Type().Key()) {
+			if val := elem.MapIndex(key.Convert(elem.Type().Key())); val.IsValid() {
+				return val.Interface(), nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("unknown field %s of type: %T", x.name, value)
+}
+
+func (x *indexExpr) eval(vm *ViewModel, scope *scope) (interface{}, error) {
+	value, err := x.x.eval(vm, scope)
+	if err != nil {
+		return nil, err
+	}
+	index, err := x.index.eval(vm, scope)
+	if err != nil {
+		return nil, err
+	}
+	elem := indirect(reflect.ValueOf(value))
+	switch elem.Kind() {
+	case reflect.Slice, reflect.Array, reflect.String:
+		i, isInt, ok := toInt(index)
+		if !ok || !isInt {
+			return nil, fmt.Errorf("invalid index of type: %T", index)
+		}
+		if i < 0 || int(i) >= elem.Len() {
+			return nil, fmt.Errorf("index out of range: %d", i)
+		}
+		return elem.Index(int(i)).Interface(), nil
+	case reflect.Map:
+		key, err := convertValue(index, elem.Type().Key())
+		if err != nil {
+			return nil, err
+		}
+		if val := elem.MapIndex(key); val.IsValid() {
+			return val.Interface(), nil
+		}
+		return reflect.Zero(elem.Type().Elem()).Interface(), nil
+	}
+	return nil, fmt.Errorf("cannot index type: %T", value)
+}
+
+func (x *callExpr) eval(vm *ViewModel, scope *scope) (interface{}, error) {
+	args := make([]interface{}, 0, len(x.args))
+	for _, arg := range x.args {
+		value, err := arg.eval(vm, scope)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, value)
+	}
+
+	var function reflect.Value
+	var values []reflect.Value
+	switch fn := x.fn.(type) {
+	case *identExpr:
+		method, ok := vm.comp.methods[fn.name]
+		if !ok {
+			return nil, fmt.Errorf("unknown method: %s", fn.name)
+		}
+		function, values = method, []reflect.Value{reflect.ValueOf(vm)}
+	case *memberExpr:
+		value, err := fn.x.eval(vm, scope)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			return nil, fmt.Errorf("method %s of nil", fn.name)
+		}
+		if function = reflect.ValueOf(value).MethodByName(fn.name); !function.IsValid() {
+			return nil, fmt.Errorf("unknown method %s of type: %T", fn.name, value)
+		}
+	default:
+		return nil, fmt.Errorf("expression is not callable")
+	}
+
+	converted, err := convertArgs(function.Type(), len(values), args)
+	if err != nil {
+		return nil, err
+	}
+	rets := function.Call(append(values, converted...))
+	if len(rets) == 0 {
+		return nil, nil
+	}
+	return rets[0].Interface(), nil
+}
+
+func (x *unaryExpr) eval(vm *ViewModel, scope *scope) (interface{}, error) {
+	value, err := x.x.eval(vm, scope)
+	if err != nil {
+		return nil, err
+	}
+	if x.op == "!" {
+		return !truthy(value), nil
+	}
+	if i, isInt, ok := toInt(value); ok && isInt {
+		return int(-i), nil
+	}
+	if f, ok := toFloat(value); ok {
+		return -f, nil
+	}
+	return nil, fmt.Errorf("invalid operation: -%T", value)
+}
+
+func (x *binaryExpr) eval(vm *ViewModel, scope *scope) (interface{}, error) {
+	left, err := x.x.eval(vm, scope)
+	if err != nil {
+		return nil, err
+	}
+	// Logical operators short circuit.
+	switch x.op {
+	case "&&":
+		if !truthy(left) {
+			return false, nil
+		}
+	case "||":
+		if truthy(left) {
+			return true, nil
+		}
+	}
+	right, err := x.y.eval(vm, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	switch x.op {
+	case "&&", "||":
+		return truthy(right), nil
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "<", "<=", ">", ">=":
+		return compare(x.op, left, right)
+	default:
+		return arithmetic(x.op, left, right)
+	}
+}
+
+// equal returns true if the values are equal, numbers are compared by value.
+// Nil pointers, slices and maps are equal to nil.
+func equal(x, y interface{}) bool {
+	if x == nil || y == nil {
+		return isNil(x) && isNil(y)
+	}
+	if xf, ok := toFloat(x); ok {
+		if yf, ok := toFloat(y); ok {
+			return xf == yf
+		}
+	}
+	return reflect.DeepEqual(x, y)
+}
+
+// compare compares numbers or strings by the operator.
+func compare(op string, x, y interface{}) (bool, error) {
+	var cmp int
+	xf, xok := toFloat(x)
+	yf, yok := toFloat(y)
+	xs, xIsStr := x.(string)
+	ys, yIsStr := y.(string)
+	switch {
+	case xok && yok:
+		cmp = compareFloat(xf, yf)
+	case xIsStr && yIsStr:
+		cmp = strings.Compare(xs, ys)
+	default:
+		return false, fmt.Errorf("invalid operation: %T %s %T", x, op, y)
+	}
+
+	switch op {
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	default:
+		return cmp >= 0, nil
+	}
+}
+
+// compareFloat returns -1, 0 or 1 as the first float is less, equal or greater.
+func compareFloat(x, y float64) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// arithmetic applies the arithmetic operator to numbers.
+// The + operator concatenates if either value is a string.
+func arithmetic(op string, x, y interface{}) (interface{}, error) {
+	if op == "+" {
+		_, xIsStr := x.(string)
+		_, yIsStr := y.(string)
+		if xIsStr || yIsStr {
+			return fmt.Sprint(x) + fmt.Sprint(y), nil
+		}
+	}
+
+	xi, xIsInt, xok := toInt(x)
+	yi, yIsInt, yok := toInt(y)
+	if xok && yok && xIsInt && yIsInt {
+		switch op {
+		case "+":
+			return int(xi + yi), nil
+		case "-":
+			return int(xi - yi), nil
+		case "*":
+			return int(xi * yi), nil
+		}
+		if yi == 0 {
+			return nil, fmt.Errorf("integer division by zero")
+		}
+		if op == "/" {
+			return int(xi / yi), nil
+		}
+		return int(xi % yi), nil
+	}
+
+	xf, xok := toFloat(x)
+	yf, yok := toFloat(y)
+	if !xok || !yok {
+		return nil, fmt.Errorf("invalid operation: %T %s %T", x, op, y)
+	}
+	switch op {
+	case "+":
+		return xf + yf, nil
+	case "-":
+		return xf - yf, nil
+	case "*":
+		return xf * yf, nil
+	case "/":
+		return xf / yf, nil
+	default:
+		return math.Mod(xf, yf), nil
+	}
+}
+
+// truthy returns true unless the value is false, zero, empty or nil.
+func truthy(value interface{}) bool {
+	elem := reflect.ValueOf(value)
+	switch elem.Kind() {
+	case reflect.Invalid:
+		return false
+	case reflect.Bool:
+		return elem.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return elem.Int() != 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return elem.Uint() != 0
+	case reflect.Float32, reflect.Float64:
+		return elem.Float() != 0
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return elem.Len() > 0
+	case reflect.Ptr, reflect.Interface, reflect.Func, reflect.Chan:
+		return !elem.IsNil()
+	default:
+		return true
+	}
+}
+
+// isNil returns true if the value is nil or a nil pointer, interface, slice, map, function or channel.
+func isNil(value interface{}) bool {
+	elem := reflect.ValueOf(value)
+	switch elem.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map, reflect.Func, reflect.Chan:
+		return elem.IsNil()
+	default:
+		return false
+	}
+}
+
+// toInt converts the number into an integer.
+// Returns false if the value is not a number, isInt is false for floats.
+func toInt(value interface{}) (i int64, isInt, ok bool) {
+	elem := reflect.ValueOf(value)
+	switch elem.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return elem.Int(), true, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return int64(elem.Uint()), true, true
+	case reflect.Float32, reflect.Float64:
+		return int64(elem.Float()), false, true
+	default:
+		return 0, false, false
+	}
+}
+
+// toFloat converts the number into a float.
+// Returns false if the value is not a number.
+func toFloat(value interface{}) (float64, bool) {
+	elem := reflect.ValueOf(value)
+	switch elem.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return elem.Float(), true
+	}
+	i, _, ok := toInt(value)
+	return float64(i), ok
+}
+
+// indirect dereferences pointers and interfaces of the value.
+func indirect(value reflect.Value) reflect.Value {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return reflect.Value{}
+		}
+		value = value.Elem()
+	}
+	return value
+}
@@ -0,0 +1,156 @@
+//go:build js && wasm
+
+package vue
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testUser struct {
+	Name string
+}
+
+func (user *testUser) Label() string {
+	return "user " + user.Name
+}
+
+type testLabeler interface {
+	Label() string
+}
+
+func testData() map[string]interface{} {
+	return map[string]interface{}{
+		"Count":    3,
+		"Price":    1.5,
+		"Name":     "go",
+		"Done":     true,
+		"Items":    []string{"a", "b", "c"},
+		"Labels":   map[string]string{"name": "Name"},
+		"Numbers":  map[int]int{1: 10},
+		"User":     &testUser{Name: "gopher"},
+		"NilUser":  (*testUser)(nil),
+		"NilItems": []string(nil),
+		"NilMap":   map[string]int(nil),
+		"Selected": testLabeler(nil),
+	}
+}
+
+func TestEval(t *testing.T) {
+	tests := []struct {
+		src  string
+		want interface{}
+	}{
+		// Precedence.
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"10 - 4 - 3", 3},
+		{"Count > 2 && Count < 4", true},
+		{"false || Count == 3 && Done", true},
+		{"1 + 2 == 3", true},
+		{"!Done || Count > 1", true},
+		// Unary minus.
+		{"-Count", -3},
+		{"-Price", -1.5},
+		{"2 - -1", 3},
+		{"-(1 + 2) * 2", -6},
+		{"!!Done", true},
+		// Strings and escapes.
+		{"'a\\'b'", "a'b"},
+		{`"say \"hi\""`, `say "hi"`},
+		{"'/user/' + Name", "/user/go"},
+		{"'n' + Count", "n3"},
+		// Numbers.
+		{"7 / 2", 3},
+		{"7 % 4", 3},
+		{"7.0 / 2", 3.5},
+		{"Count == 3.0", true},
+		{"Name < 'h'", true},
+		// Nil comparisons.
+		{"User != nil", true},
+		{"NilUser == nil", true},
+		{"NilUser != nil", false},
+		{"NilItems == nil", true},
+		{"NilMap == nil", true},
+		{"Selected == nil", true},
+		{"nil == nil", true},
+		{"Count == nil", false},
+		{"NilUser && NilUser.Name", false},
+		// Members, indexes and calls.
+		{"User.Name", "gopher"},
+		{"User.Label()", "user gopher"},
+		{"Items[1]", "b"},
+		{"Items[Count - 1]", "c"},
+		{"Labels['name']", "Name"},
+		{"Labels.name", "Name"},
+		{"Labels['missing']", ""},
+		{"Numbers[1]", 10},
+		{"Numbers[2]", 0},
+	}
+	vm := &ViewModel{}
+	for _, test := range tests {
+		x, err := parseExpr(test.src)
+		if err != nil {
+			t.Errorf("parseExpr(%q) error: %v", test.src, err)
+			continue
+		}
+		got, err := x.eval(vm, newScope(testData()))
+		if err != nil {
+			t.Errorf("eval(%q) error: %v", test.src, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("eval(%q) = %#v, want %#v", test.src, got, test.want)
+		}
+	}
+}
+
+func TestEvalError(t *testing.T) {
+	tests := []string{
+		"Count / 0",
+		"Count % 0",
+		"Missing",
+		"User.Missing",
+		"NilUser.Name",
+		"Selected.Label()",
+		"Items[3]",
+		"Items[-1]",
+		"Items['a']",
+		"Count[0]",
+		"Name - 1",
+		"Name < 1",
+		"-Name",
+		"Missing()",
+	}
+	vm := &ViewModel{comp: &Comp{methods: make(map[string]reflect.Value, 0)}}
+	for _, src := range tests {
+		x, err := parseExpr(src)
+		if err != nil {
+			t.Errorf("parseExpr(%q) error: %v", src, err)
+			continue
+		}
+		if got, err := x.eval(vm, newScope(testData())); err == nil {
+			t.Errorf("eval(%q) = %#v, want error", src, got)
+		}
+	}
+}
+
+func TestParseExprError(t *testing.T) {
+	tests := []string{
+		"",
+		"1 +",
+		"(1 + 2",
+		"Items[0",
+		"Format(1 2)",
+		"'unterminated",
+		"User.",
+		"Count = 1",
+		"1 2",
+		"#",
+	}
+	for _, src := range tests {
+		if _, err := parseExpr(src); err == nil {
+			t.Errorf("parseExpr(%q) want error", src)
+		}
+	}
+}
@@ -1,5 +1,7 @@
 module github.com/norunners/vue
 
+go 1.27.1
+
 require (
 	github.com/cbroglie/mustache v1.0.1
 	github.com/gowasm/go-js-dom v0.0.3
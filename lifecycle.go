@@ -0,0 +1,58 @@
+package vue
+
+// hook is a lifecycle hook of components.
+type hook int
+
+const (
+	created hook = iota
+	mounted
+	beforeUpdate
+	updated
+	beforeDestroy
+	destroyed
+)
+
+// hookNames are the names of the lifecycle hooks, e.g. for errors.
+var hookNames = map[hook]string{
+	created:       "created",
+	mounted:       "mounted",
+	beforeUpdate:  "beforeUpdate",
+	updated:       "updated",
+	beforeDestroy: "beforeDestroy",
+	destroyed:     "destroyed",
+}
+
+// callHook calls the functions of the lifecycle hook with the context.
+func (vm *ViewModel) callHook(hook hook) {
+	for _, function := range vm.comp.hooks[hook] {
+		vm.callHookFunc(hook, function)
+	}
+}
+
+// callHookFunc calls the function of the lifecycle hook with the context.
+// A panic of the function is handled without skipping the other functions.
+func (vm *ViewModel) callHookFunc(hook hook, function func(Context)) {
+	defer vm.catch(hookNames[hook] + " hook")
+	function(vm)
+}
+
+// mount recursively mounts the subcomponents then the view model unless it is mounted.
+func (vm *ViewModel) mount() {
+	vm.subs.mount()
+	vm.slotSubs.mount()
+	if vm.mounted {
+		return
+	}
+	vm.mounted = true
+	vm.callHook(mounted)
+}
+
+// destroy recursively destroys the subcomponents then releases the view model.
+func (vm *ViewModel) destroy() {
+	vm.callHook(beforeDestroy)
+	vm.subs.destroy()
+	vm.slotSubs.destroy()
+	vm.release()
+	vm.destroyed = true
+	vm.callHook(destroyed)
+}
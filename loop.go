@@ -0,0 +1,117 @@
+package vue
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+)
+
+// loopExpr matches the value of the vue for attribute.
+// For example: Item in Items, (Item, Index) in Items or (Value, Key, Index) in Map
+var loopExpr = regexp.MustCompile(`^\s*(?:\(\s*(\w+)\s*(?:,\s*(\w+)\s*)?(?:,\s*(\w+)\s*)?\)|(\w+))\s+(?:in|of)\s+(.+?)\s*$`)
+
+// loop is the parsed value of the vue for attribute.
+// The source is the expression of the iterable.
+type loop struct {
+	value, key, index string
+	src               string
+}
+
+// loopItem is an iteration of a loop.
+type loopItem struct {
+	value, key interface{}
+	index      int
+}
+
+// parseLoop parses the value of the vue for attribute.
+func parseLoop(value string) (loop, error) {
+	match := loopExpr.FindStringSubmatch(value)
+	if match == nil {
+		return loop{}, fmt.Errorf("invalid v-for expression: %s", value)
+	}
+	if match[4] != "" {
+		return loop{value: match[4], src: match[5]}, nil
+	}
+	return loop{value: match[1], key: match[2], index: match[3], src: match[5]}, nil
+}
+
+// vars maps the declared loop variables to the values of the item.
+// The key of a slice, array or range is the index.
+func (loop loop) vars(item loopItem) map[string]interface{} {
+	vars := map[string]interface{}{loop.value: item.value}
+	if loop.key != "" {
+		vars[loop.key] = item.key
+	}
+	if loop.index != "" {
+		vars[loop.index] = item.index
+	}
+	return vars
+}
+
+// loopItems returns the items to iterate from the value.
+// Slices and arrays are iterated by index, maps in sorted key order and integers as a range from one.
+func loopItems(value interface{}) ([]loopItem, error) {
+	values := reflect.Indirect(reflect.ValueOf(value))
+	if !values.IsValid() {
+		return nil, nil
+	}
+
+	var items []loopItem
+	switch values.Kind() {
+	case reflect.Slice, reflect.Array:
+		n := values.Len()
+		items = make([]loopItem, 0, n)
+		for i := 0; i < n; i++ {
+			items = append(items, loopItem{value: values.Index(i).Interface(), key: i, index: i})
+		}
+	case reflect.Map:
+		keys := values.MapKeys()
+		sortValues(keys)
+		items = make([]loopItem, 0, len(keys))
+		for i, key := range keys {
+			items = append(items, loopItem{value: values.MapIndex(key).Interface(), key: key.Interface(), index: i})
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		items = rangeItems(int(values.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		items = rangeItems(int(values.Uint()))
+	default:
+		return nil, fmt.Errorf("v-for cannot iterate over type: %T", value)
+	}
+	return items, nil
+}
+
+// rangeItems returns the items of a range from one to n.
+// Negative ranges have no items.
+func rangeItems(n int) []loopItem {
+	if n < 0 {
+		n = 0
+	}
+	items := make([]loopItem, 0, n)
+	for i := 0; i < n; i++ {
+		items = append(items, loopItem{value: i + 1, key: i, index: i})
+	}
+	return items
+}
+
+// sortValues sorts the values for a deterministic order, e.g. map keys.
+func sortValues(values []reflect.Value) {
+	sort.Slice(values, func(i, j int) bool {
+		a, b := values[i], values[j]
+		switch a.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return a.Int() < b.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return a.Uint() < b.Uint()
+		case reflect.Float32, reflect.Float64:
+			return a.Float() < b.Float()
+		case reflect.String:
+			return a.String() < b.String()
+		case reflect.Bool:
+			return !a.Bool() && b.Bool()
+		default:
+			return fmt.Sprint(a.Interface()) < fmt.Sprint(b.Interface())
+		}
+	})
+}
@@ -0,0 +1,96 @@
+//go:build js && wasm
+
+package vue
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLoop(t *testing.T) {
+	tests := []struct {
+		value string
+		want  loop
+	}{
+		{"Item in Items", loop{value: "Item", src: "Items"}},
+		{"Item of Items", loop{value: "Item", src: "Items"}},
+		{"  Item   in   Items  ", loop{value: "Item", src: "Items"}},
+		{"(Item, Index) in Items", loop{value: "Item", key: "Index", src: "Items"}},
+		{"(Value, Key, Index) in Map", loop{value: "Value", key: "Key", index: "Index", src: "Map"}},
+		{"( Item ) in User.Items", loop{value: "Item", src: "User.Items"}},
+		{"N in Count + 1", loop{value: "N", src: "Count + 1"}},
+	}
+	for _, test := range tests {
+		got, err := parseLoop(test.value)
+		if err != nil {
+			t.Errorf("parseLoop(%q) error: %v", test.value, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("parseLoop(%q) = %+v, want %+v", test.value, got, test.want)
+		}
+	}
+}
+
+func TestParseLoopError(t *testing.T) {
+	tests := []string{
+		"",
+		"Items",
+		"in Items",
+		"Item in",
+		"(Item, Index in Items",
+		"(A, B, C, D) in Items",
+	}
+	for _, value := range tests {
+		if got, err := parseLoop(value); err == nil {
+			t.Errorf("parseLoop(%q) = %+v, want error", value, got)
+		}
+	}
+}
+
+func TestLoopItems(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  []loopItem
+	}{
+		{nil, nil},
+		{(*[]string)(nil), nil},
+		{[]string{"a", "b"}, []loopItem{{value: "a", key: 0, index: 0}, {value: "b", key: 1, index: 1}}},
+		{&[]int{7}, []loopItem{{value: 7, key: 0, index: 0}}},
+		{[2]bool{true, false}, []loopItem{{value: true, key: 0, index: 0}, {value: false, key: 1, index: 1}}},
+		{map[string]int{"b": 2, "a": 1}, []loopItem{{value: 1, key: "a", index: 0}, {value: 2, key: "b", index: 1}}},
+		{map[int]string{3: "c", 1: "a"}, []loopItem{{value: "a", key: 1, index: 0}, {value: "c", key: 3, index: 1}}},
+		{3, []loopItem{{value: 1, key: 0, index: 0}, {value: 2, key: 1, index: 1}, {value: 3, key: 2, index: 2}}},
+		{uint8(1), []loopItem{{value: 1, key: 0, index: 0}}},
+		{0, []loopItem{}},
+		{-2, []loopItem{}},
+	}
+	for _, test := range tests {
+		got, err := loopItems(test.value)
+		if err != nil {
+			t.Errorf("loopItems(%#v) error: %v", test.value, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("loopItems(%#v) = %+v, want %+v", test.value, got, test.want)
+		}
+	}
+}
+
+func TestLoopItemsError(t *testing.T) {
+	tests := []interface{}{"abc", 1.5, true, struct{}{}}
+	for _, value := range tests {
+		if got, err := loopItems(value); err == nil {
+			t.Errorf("loopItems(%#v) = %+v, want error", value, got)
+		}
+	}
+}
+
+func TestLoopVars(t *testing.T) {
+	loop := loop{value: "Value", key: "Key", index: "Index"}
+	got := loop.vars(loopItem{value: "v", key: "k", index: 2})
+	want := map[string]interface{}{"Value": "v", "Key": "k", "Index": 2}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("vars = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,266 @@
+package vue
+
+import (
+	"fmt"
+	"github.com/gowasm/go-js-dom"
+	"golang.org/x/net/html"
+	"reflect"
+	"strings"
+)
+
+// modelProp is the prop of a subcomponent bound by the vue model attribute.
+const modelProp = "Value"
+
+// model binds a form element or a subcomponent to a field of the parent by the vue model attribute.
+// The subcomponent receives the value prop and emits input or update events.
+// The field path is resolved in the scope of the element, e.g. Item.Done of a loop.
+type model struct {
+	vm     *ViewModel
+	field  string
+	expr   expr
+	scope  *scope
+	modSet map[string]struct{}
+}
+
+// update converts and sets the value of the field then schedules a render of the parent.
+func (model *model) update(value interface{}) {
+	vm := model.vm
+	if _, _, ok := model.scope.lookup(rootName(model.expr)); !ok {
+		fieldType, err := vm.fieldType(model.field)
+		if err != nil {
+			vm.handleError(vm.newError(err, "", "v-model "+model.field))
+			return
+		}
+		newVal, err := convertModel(value, fieldType, model.modSet)
+		if err != nil {
+			err = fmt.Errorf("invalid value for field %s: %v", model.field, err)
+			vm.handleError(vm.newError(err, "", "v-model "+model.field))
+			return
+		}
+		vm.Set(model.field, newVal.Interface())
+		vm.schedule()
+		return
+	}
+
+	target, field, err := vm.target(model.expr, model.scope)
+	if err != nil {
+		vm.handleError(vm.newError(err, "", "v-model "+model.field))
+		return
+	}
+	newVal, err := convertModel(value, target.Type(), model.modSet)
+	if err != nil {
+		err = fmt.Errorf("invalid value for field %s: %v", model.field, err)
+		vm.handleError(vm.newError(err, "", "v-model "+model.field))
+		return
+	}
+	target.Set(newVal)
+	vm.markField(field)
+	vm.mapField(field, reflect.Indirect(vm.data).FieldByName(field).Interface())
+	vm.schedule()
+}
+
+// target resolves the field path in the scope into the settable value and the data field to be marked.
+// Loop variables resolve to the iterated element, e.g. Item.Done -> Items[i].Done.
+func (vm *ViewModel) target(x expr, scope *scope) (reflect.Value, string, error) {
+	switch x := x.(type) {
+	case *identExpr:
+		origin, declared, ok := scope.lookup(x.name)
+		if !ok {
+			value, err := vm.field(x.name)
+			return value, x.name, err
+		}
+		values, field, err := vm.target(origin.src, declared.parent)
+		if err != nil {
+			return reflect.Value{}, "", err
+		}
+		values = reflect.Indirect(values)
+		if !values.IsValid() {
+			return reflect.Value{}, "", fmt.Errorf("cannot set loop variable of nil: %s", x.name)
+		}
+		index, ok := origin.key.(int)
+		if kind := values.Kind(); !ok || kind != reflect.Slice && kind != reflect.Array {
+			return reflect.Value{}, "", fmt.Errorf("cannot set loop variable %s of type: %s", x.name, values.Type())
+		}
+		if elem := values.Index(index); elem.CanSet() {
+			return elem, field, nil
+		}
+		return reflect.Value{}, "", fmt.Errorf("cannot set loop variable: %s", x.name)
+	case *memberExpr:
+		value, field, err := vm.target(x.x, scope)
+		if err != nil {
+			return reflect.Value{}, "", err
+		}
+		value = reflect.Indirect(value)
+		if !value.IsValid() {
+			return reflect.Value{}, "", fmt.Errorf("cannot set field %s of nil", x.name)
+		}
+		if value.Kind() == reflect.Struct {
+			if member := value.FieldByName(x.name); member.CanSet() {
+				return member, field, nil
+			}
+		}
+		return reflect.Value{}, "", fmt.Errorf("unknown field %s of type: %s", x.name, value.Type())
+	default:
+		return reflect.Value{}, "", fmt.Errorf("not a field path")
+	}
+}
+
+// rootName returns the name of the first identifier of the field path.
+// For example: Item.Done -> Item
+func rootName(x expr) string {
+	for {
+		switch path := x.(type) {
+		case *identExpr:
+			return path.name
+		case *memberExpr:
+			x = path.x
+		default:
+			return ""
+		}
+	}
+}
+
+// modelEvent returns the event type which updates the vue model of the form element.
+// Checkboxes, radios, selects and lazy models change, other elements input.
+func modelEvent(node *html.Node, mods string) string {
+	_, lazy := modSet(strings.Split(mods, "."))["lazy"]
+	switch typ := inputType(node); {
+	case lazy, node.Data == "select", typ == "checkbox", typ == "radio":
+		return "change"
+	default:
+		return "input"
+	}
+}
+
+// inputType returns the lowercase type of an input element.
+// The type is empty for other elements.
+func inputType(node *html.Node) string {
+	if node.Data != "input" {
+		return ""
+	}
+	typ, _ := getAttr(node, "type")
+	return strings.ToLower(typ)
+}
+
+// checkOption checks a checkbox or radio which matches the value.
+// For example, a checkbox is checked by true or a slice containing its value.
+func checkOption(node *html.Node, value interface{}) {
+	option, _ := getAttr(node, "value")
+	checked := false
+	switch value := value.(type) {
+	case bool:
+		checked = value
+	case []string:
+		checked = contains(value, option)
+	default:
+		checked = fmt.Sprintf("%v", value) == option
+	}
+	if checked {
+		putAttr(node, "checked", "")
+	}
+}
+
+// selectOptions recursively selects the options of the select which match the value.
+// A multiple select is expected to have a slice value.
+func selectOptions(node *html.Node, value interface{}) {
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != html.ElementNode {
+			continue
+		}
+		if child.Data != "option" {
+			selectOptions(child, value)
+			continue
+		}
+		option, ok := getAttr(child, "value")
+		if !ok {
+			option = strings.TrimSpace(nodeText(child))
+		}
+		var selected bool
+		if values, ok := value.([]string); ok {
+			selected = contains(values, option)
+		} else {
+			selected = fmt.Sprintf("%v", value) == option
+		}
+		if selected {
+			putAttr(child, "selected", "")
+		}
+	}
+}
+
+// modelValue reads the value of the form element for the vue model.
+// The current value of a checkbox bound to a slice is toggled by its value.
+func modelValue(target dom.Element, current interface{}) interface{} {
+	elem := target.Underlying()
+	switch {
+	case elem.Get("multiple").Truthy() && strings.EqualFold(target.TagName(), "select"):
+		options := elem.Get("selectedOptions")
+		n := options.Length()
+		values := make([]string, 0, n)
+		for i := 0; i < n; i++ {
+			values = append(values, options.Index(i).Get("value").String())
+		}
+		return values
+	case strings.EqualFold(elem.Get("type").String(), "checkbox"):
+		checked := elem.Get("checked").Bool()
+		values, ok := current.([]string)
+		if !ok {
+			return checked
+		}
+		option := elem.Get("value").String()
+		if checked && !contains(values, option) {
+			return append(values, option)
+		}
+		if !checked {
+			return remove(values, option)
+		}
+		return values
+	default:
+		return elem.Get("value").String()
+	}
+}
+
+// modelAttribute finds the vue model attribute of the element for the event type.
+// The field reference and modifiers are returned, or false if the attribute is not found.
+// For example: model.input.trim="1:Name" -> "1:Name", {"trim"}
+func modelAttribute(elem dom.Element, typ string) (string, map[string]struct{}, bool) {
+	for attrKey, attrVal := range elem.Attributes() {
+		mods := strings.Split(strings.TrimPrefix(attrKey, modelAttr), ".")
+		if strings.HasPrefix(attrKey, modelAttr) && mods[0] == typ {
+			return attrVal, modSet(mods[1:]), true
+		}
+	}
+	return "", nil, false
+}
+
+// nodeText returns the text content of the html node.
+func nodeText(node *html.Node) string {
+	if node.Type == html.TextNode {
+		return node.Data
+	}
+	sb := &strings.Builder{}
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		sb.WriteString(nodeText(child))
+	}
+	return sb.String()
+}
+
+// contains returns true if the values contain the value.
+func contains(values []string, value string) bool {
+	for _, val := range values {
+		if val == value {
+			return true
+		}
+	}
+	return false
+}
+
+// remove returns the values without the value.
+func remove(values []string, value string) []string {
+	removed := make([]string, 0, len(values))
+	for _, val := range values {
+		if val != value {
+			removed = append(removed, val)
+		}
+	}
+	return removed
+}
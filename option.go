@@ -74,6 +74,17 @@ func Computed(name string, function interface{}) Option {
 	}
 }
 
+// ComputedSetter is the computed setter option for components.
+// The given function is registered as the setter of the computed property, which makes it writable.
+// The function is required to accept context and the value, e.g. set by the vue model attribute.
+// For example: func(vctx vue.Context, value Type)
+func ComputedSetter(name string, function interface{}) Option {
+	return func(comp *Comp) {
+		fn := reflect.ValueOf(function)
+		comp.setters[name] = fn
+	}
+}
+
 // Computeds is the computeds option for components.
 // The given functions are registered as computed properties for the component.
 // The functions are required to accept context and return a value.
@@ -89,14 +100,14 @@ func Computeds(functions ...interface{}) Option {
 }
 
 // Watch is the watch option for components.
-// The given function is registered as a watcher for the data field.
+// The given function is registered as a watcher for the data field or field path, e.g. User.Address.City.
 // All data fields are watchable, e.g. data, props and computed.
+// Watchers are called once per update before rendering, see the Deep and Immediate watch options.
 // The function is required to accept context and both the new and old values.
 // For example: func(vctx vue.Context, newVal, oldVal Type)
-func Watch(field string, function interface{}) Option {
+func Watch(field string, function interface{}, options ...WatchOption) Option {
 	return func(comp *Comp) {
-		fn := reflect.ValueOf(function)
-		comp.watchers[field] = fn
+		comp.watchers = append(comp.watchers, newWatcher(field, function, options))
 	}
 }
 
@@ -104,16 +115,107 @@ func Watch(field string, function interface{}) Option {
 func Sub(element string, sub *Comp) Option {
 	return func(comp *Comp) {
 		sub.isSub = true
+		if sub.name == "" {
+			sub.name = element
+		}
 		comp.subs[element] = sub
 	}
 }
 
+// Emits is the emits option for subcomponents.
+// The events are declared to be emitted, other events are reported as errors.
+// The vue on attribute of the subcomponent element listens to declared events,
+// other events are listened to on the root element of the subcomponent.
+func Emits(events ...string) Option {
+	return func(sub *Comp) {
+		for _, event := range events {
+			sub.emits[event] = struct{}{}
+		}
+	}
+}
+
 // Props is the props option for subcomponents.
+// Subcomponents bound by the vue model attribute receive the Value prop
+// and update the parent by emitting input or update events with the new value.
 func Props(props ...string) Option {
 	return func(sub *Comp) {
-		for _, prop := range props {
-			sub.props[prop] = struct{}{}
+		for _, name := range props {
+			sub.props[name] = &prop{name: name}
+		}
+	}
+}
+
+// Prop is the prop option for subcomponents with prop options, e.g. the type, default and required.
+// Props are validated when passed by the parent.
+// For example: vue.Prop("Size", vue.PropType(reflect.Int), vue.Default(10), vue.Required())
+func Prop(name string, options ...PropOption) Option {
+	return func(sub *Comp) {
+		prop := &prop{name: name}
+		for _, option := range options {
+			option(prop)
 		}
+		sub.props[name] = prop
+	}
+}
+
+// Created is the created hook option for components.
+// The function is called after the data is created, before the first render.
+func Created(function func(Context)) Option {
+	return addHook(created, function)
+}
+
+// Mounted is the mounted hook option for components.
+// The function is called after the component is first rendered into the document.
+func Mounted(function func(Context)) Option {
+	return addHook(mounted, function)
+}
+
+// BeforeUpdate is the before update hook option for components.
+// The function is called before the mounted component is rendered.
+func BeforeUpdate(function func(Context)) Option {
+	return addHook(beforeUpdate, function)
+}
+
+// Updated is the updated hook option for components.
+// The function is called after the mounted component is rendered.
+func Updated(function func(Context)) Option {
+	return addHook(updated, function)
+}
+
+// BeforeDestroy is the before destroy hook option for components.
+// The function is called before the subcomponent is unmounted, e.g. to stop timers.
+func BeforeDestroy(function func(Context)) Option {
+	return addHook(beforeDestroy, function)
+}
+
+// Destroyed is the destroyed hook option for components.
+// The function is called after the subcomponent is unmounted and its event listeners are removed.
+func Destroyed(function func(Context)) Option {
+	return addHook(destroyed, function)
+}
+
+// ErrorHandler is the error handler option for components.
+// The function receives errors of the component and its subcomponents
+// which are not handled by a nearer error handler nor captured by an error captured hook.
+func ErrorHandler(function func(vctx Context, err *Error)) Option {
+	return func(comp *Comp) {
+		comp.errorHandler = function
+	}
+}
+
+// ErrorCaptured is the error captured hook option for components.
+// The function is called with errors of descendant subcomponents.
+// The function returns false to stop the error from propagating further, as in Vue.
+func ErrorCaptured(function func(vctx Context, err *Error) bool) Option {
+	return func(comp *Comp) {
+		comp.errorCaptured = append(comp.errorCaptured, function)
+	}
+}
+
+// addHook adds the function to the lifecycle hook of the component.
+func addHook(hook hook, function func(Context)) Option {
+	return func(comp *Comp) {
+		comp.hooks[hook] = append(comp.hooks[hook], function)
 	}
 }
 
@@ -0,0 +1,245 @@
+package vue
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// kindTypes are the types of the kinds into which string literals are parsed for props.
+var kindTypes = map[reflect.Kind]reflect.Type{
+	reflect.Bool:    reflect.TypeOf(false),
+	reflect.Int:     reflect.TypeOf(int(0)),
+	reflect.Int8:    reflect.TypeOf(int8(0)),
+	reflect.Int16:   reflect.TypeOf(int16(0)),
+	reflect.Int32:   reflect.TypeOf(int32(0)),
+	reflect.Int64:   reflect.TypeOf(int64(0)),
+	reflect.Uint:    reflect.TypeOf(uint(0)),
+	reflect.Uint8:   reflect.TypeOf(uint8(0)),
+	reflect.Uint16:  reflect.TypeOf(uint16(0)),
+	reflect.Uint32:  reflect.TypeOf(uint32(0)),
+	reflect.Uint64:  reflect.TypeOf(uint64(0)),
+	reflect.Float32: reflect.TypeOf(float32(0)),
+	reflect.Float64: reflect.TypeOf(float64(0)),
+	reflect.String:  reflect.TypeOf(""),
+}
+
+// PropOption is an option for props.
+type PropOption func(*prop)
+
+// prop is a declared prop of a subcomponent.
+// Props without kinds nor type accept values of any type, typed props are fields of PropsOf.
+type prop struct {
+	name      string
+	typ       reflect.Type
+	kinds     []reflect.Kind
+	def       interface{}
+	hasDef    bool
+	required  bool
+	validator func(value interface{}) bool
+}
+
+// PropsOf is the typed props option for subcomponents.
+// The exported fields of the struct are props, received into a new struct by each instance, see Context.Props.
+// Props are passed by the attribute of the prop tag, otherwise the lower or kebab case field name.
+// The values of the given struct are defaults, the required tag option declares required props.
+// For example: vue.PropsOf(&ButtonProps{Size: 10}) with Label string `prop:"label,required"`
+// The props are required to be a pointer to a struct, otherwise the component reports an error.
+func PropsOf(props interface{}) Option {
+	return func(sub *Comp) {
+		value := reflect.Indirect(reflect.ValueOf(props))
+		if value.Kind() != reflect.Struct {
+			sub.err = sub.newError(fmt.Errorf("props is not a struct: %T", props), "", "PropsOf")
+			return
+		}
+		typ := value.Type()
+		sub.propsType = typ
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			opts := strings.Split(field.Tag.Get("prop"), ",")
+			attr := opts[0]
+			if attr == "" {
+				attr = strings.ToLower(field.Name)
+			}
+			prop := &prop{name: field.Name, typ: field.Type, def: value.Field(i).Interface(), hasDef: true}
+			for _, opt := range opts[1:] {
+				if opt == "required" {
+					prop.required = true
+				}
+			}
+			sub.props[field.Name] = prop
+			sub.propAttrs[attr] = field.Name
+		}
+	}
+}
+
+// PropType is the type prop option.
+// The prop is required to be of one of the kinds, numbers are converted between kinds
+// and string literals are parsed into the first kind, e.g. "10" into 10.
+func PropType(kinds ...reflect.Kind) PropOption {
+	return func(prop *prop) {
+		prop.kinds = append(prop.kinds, kinds...)
+	}
+}
+
+// Default is the default prop option.
+// The value is received when the prop is not passed. The value is shared by all instances.
+func Default(value interface{}) PropOption {
+	return func(prop *prop) {
+		prop.def, prop.hasDef = value, true
+	}
+}
+
+// Required is the required prop option.
+// The prop is required to be passed.
+func Required() PropOption {
+	return func(prop *prop) {
+		prop.required = true
+	}
+}
+
+// Validator is the validator prop option.
+// The function returns true if the value of the prop is valid.
+func Validator(function func(value interface{}) bool) PropOption {
+	return func(prop *prop) {
+		prop.validator = function
+	}
+}
+
+// validateProps validates the passed props against the declared props, then returns the props with defaults.
+// Values are converted into the kind of the prop as needed.
+// Violations are reported to the error handlers, the values are received as is.
+// Props are reported when the instance is created and when the passed value changes, not by every render.
+func (vm *ViewModel) validateProps(props map[string]interface{}) map[string]interface{} {
+	passed := vm.passed
+	if props == nil {
+		props = make(map[string]interface{}, 0)
+	}
+	vm.passed = props
+
+	validated := make(map[string]interface{}, len(props))
+	for field, value := range props {
+		validated[field] = value
+	}
+
+	for name, prop := range vm.comp.props {
+		value, ok := validated[name]
+		oldVal, oldOk := passed[name]
+		report := passed == nil || ok != oldOk || !sameValue(value, oldVal)
+		if !ok && prop.required {
+			if report {
+				vm.handleError(vm.newError(fmt.Errorf("missing required prop: %s", name), "", "prop "+name))
+			}
+			continue
+		}
+		if !ok && !prop.hasDef {
+			continue
+		}
+		if !ok {
+			value = prop.def
+		}
+
+		value, err := prop.convert(value)
+		if err == nil && report && prop.validator != nil && !prop.validator(value) {
+			err = fmt.Errorf("invalid value for prop %s: %v", name, value)
+		}
+		if err != nil && report {
+			vm.handleError(vm.newError(err, "", "prop "+name))
+		}
+		validated[name] = value
+	}
+	if vm.comp.propsType != nil {
+		vm.setTypedProps(validated)
+	}
+	return validated
+}
+
+// setTypedProps sets the fields of the typed props to the validated props.
+// The struct is created once and updated in place by the parent.
+func (vm *ViewModel) setTypedProps(props map[string]interface{}) {
+	if !vm.typedProps.IsValid() {
+		vm.typedProps = reflect.New(vm.comp.propsType)
+	}
+	elem := vm.typedProps.Elem()
+	for name, prop := range vm.comp.props {
+		value, ok := props[name]
+		if prop.typ == nil || !ok {
+			continue
+		}
+		if val, err := convertValue(value, prop.typ); err == nil {
+			elem.FieldByName(name).Set(val)
+		}
+	}
+}
+
+// propName returns the name of the prop passed by the attribute.
+// Kebab case attributes are converted into the title style of fields.
+// For example: v-bind:item-count -> ItemCount
+func (comp *Comp) propName(attr string) string {
+	if name, ok := comp.propAttrs[attr]; ok {
+		return name
+	}
+	return kebabTitle(attr)
+}
+
+// convert converts the value into the type or kinds of the prop.
+// Returns the value as is with an error if the value is not of the type nor kinds.
+func (prop *prop) convert(value interface{}) (interface{}, error) {
+	if value == nil {
+		return value, nil
+	}
+	if prop.typ != nil {
+		return prop.convertType(value)
+	}
+	if len(prop.kinds) == 0 {
+		return value, nil
+	}
+	val := reflect.ValueOf(value)
+	for _, kind := range prop.kinds {
+		if val.Kind() == kind {
+			return value, nil
+		}
+	}
+
+	typ, ok := kindTypes[prop.kinds[0]]
+	switch {
+	case ok && isNumber(val.Kind()) && isNumber(typ.Kind()):
+		return val.Convert(typ).Interface(), nil
+	case ok && val.Kind() == reflect.String:
+		parsed, err := parseValue(val.String(), typ)
+		if err != nil {
+			return value, fmt.Errorf("invalid value for prop %s: %v", prop.name, err)
+		}
+		return parsed.Interface(), nil
+	}
+	return value, fmt.Errorf("invalid type for prop %s: %T is not %s", prop.name, value, prop.kindNames())
+}
+
+// convertType converts the value into the type of a typed prop, string literals are parsed.
+func (prop *prop) convertType(value interface{}) (interface{}, error) {
+	if s, ok := value.(string); ok && prop.typ.Kind() != reflect.String {
+		parsed, err := parseValue(s, prop.typ)
+		if err != nil {
+			return value, fmt.Errorf("invalid value for prop %s: %v", prop.name, err)
+		}
+		return parsed.Interface(), nil
+	}
+	converted, err := convertValue(value, prop.typ)
+	if err != nil {
+		return value, fmt.Errorf("invalid type for prop %s: %v", prop.name, err)
+	}
+	return converted.Interface(), nil
+}
+
+// kindNames returns the names of the kinds of the prop, e.g. for errors.
+// For example: int or string
+func (prop *prop) kindNames() string {
+	names := make([]string, 0, len(prop.kinds))
+	for _, kind := range prop.kinds {
+		names = append(names, kind.String())
+	}
+	return strings.Join(names, " or ")
+}
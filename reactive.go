@@ -0,0 +1,124 @@
+package vue
+
+import (
+	"golang.org/x/net/html"
+)
+
+// tracking records the fields read and the handlers registered while executing, e.g. a template element.
+// Reads of data without fields, e.g. by Data, are untracked.
+type tracking struct {
+	parent    *tracking
+	fields    map[string]struct{}
+	handlers  map[int]*handler
+	untracked bool
+}
+
+// tracked is the output of an executed template element.
+// The output is reused by renders until a field read by the element changes.
+type tracked struct {
+	tracking *tracking
+	nodes    []*html.Node
+}
+
+// track records the field as read by the current tracking.
+func (vm *ViewModel) track(field string) {
+	if vm.tracking != nil {
+		vm.tracking.fields[field] = struct{}{}
+	}
+}
+
+// startTracking starts a new tracking nested in the current tracking.
+func (vm *ViewModel) startTracking() *tracking {
+	vm.tracking = &tracking{
+		parent:   vm.tracking,
+		fields:   make(map[string]struct{}, 0),
+		handlers: make(map[int]*handler, 0),
+	}
+	return vm.tracking
+}
+
+// stopTracking stops the current tracking which is merged into its parent.
+func (vm *ViewModel) stopTracking() {
+	tracking := vm.tracking
+	vm.tracking, tracking.parent = tracking.parent, nil
+	tracking.merge(vm.tracking)
+}
+
+// merge merges the fields and handlers into the tracking unless it is nil.
+func (tracking *tracking) merge(into *tracking) {
+	if into == nil {
+		return
+	}
+	for field := range tracking.fields {
+		into.fields[field] = struct{}{}
+	}
+	for id, handler := range tracking.handlers {
+		into.handlers[id] = handler
+	}
+	into.untracked = into.untracked || tracking.untracked
+}
+
+// changed returns true if a tracked field changed or the data was read untracked.
+func (tracking *tracking) changed(fields map[string]struct{}) bool {
+	if tracking.untracked {
+		return true
+	}
+	for field := range tracking.fields {
+		if _, ok := fields[field]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// markData marks the data as mutated without Set, or read untracked while tracking.
+// All cached computed are invalidated by mutations.
+func (vm *ViewModel) markData() {
+	if vm.tracking != nil {
+		vm.tracking.untracked = true
+	} else {
+		vm.untracked = true
+		vm.invalidate("")
+	}
+}
+
+// markField marks the field as changed for the next render.
+// Cached computed which read the field are invalidated.
+func (vm *ViewModel) markField(field string) {
+	vm.dirty[field] = struct{}{}
+	vm.invalidate(field)
+}
+
+// executeTracked executes the template element with tracking.
+// The output of the last render is reused unless the render is full or a tracked field changed.
+func (vm *ViewModel) executeTracked(tmpl *tmplNode, scope *scope, parent *html.Node) error {
+	if last, ok := vm.cache[tmpl]; ok && !vm.full && !last.tracking.changed(vm.changed) {
+		for _, node := range last.nodes {
+			if node.Parent != nil {
+				node.Parent.RemoveChild(node)
+			}
+			parent.AppendChild(node)
+		}
+		for id, handler := range last.tracking.handlers {
+			vm.handlers[id] = handler
+		}
+		last.tracking.merge(vm.tracking)
+		vm.rendered[tmpl] = last
+		return nil
+	}
+
+	tracking := vm.startTracking()
+	prev := parent.LastChild
+	err := vm.executeDirectives(tmpl, 0, scope, parent)
+	vm.stopTracking()
+	if err != nil {
+		return err
+	}
+
+	var nodes []*html.Node
+	for node := parent.LastChild; node != nil && node != prev; node = node.PrevSibling {
+		nodes = append([]*html.Node{node}, nodes...)
+	}
+	vm.rendered[tmpl] = &tracked{tracking: tracking, nodes: nodes}
+	return nil
+}
@@ -6,26 +6,75 @@ import (
 )
 
 // render executes and renders the prepared state.
+// Watchers of changed values are called before rendering.
+// Only elements which read changed fields are executed unless the data was mutated without Set.
+// Slot content is executed by every render since it reads the fields of the parent.
+// The update hooks are called once the view model is mounted.
+// On errors, the previous render is kept as a fallback.
 func (vm *ViewModel) render() {
+	defer vm.catch("render")
+	vm.tracking = nil
 	vm.mapState()
-	node := vm.execute(vm.state)
-	vm.subs.reset()
-	if vm.comp.isSub {
+	for i := 0; vm.callWatchers(); i++ {
+		if i == maxWatches {
+			vm.handleError(vm.newError(fmt.Errorf("exceeded %d rounds of watchers", maxWatches), "", "watch"))
+			break
+		}
+		vm.mapState()
+	}
+	if !vm.untracked && len(vm.dirty) == 0 && vm.cache != nil && len(vm.slots) == 0 {
+		return
+	}
+	if vm.mounted {
+		untracked := vm.untracked
+		vm.callHook(beforeUpdate)
+		if vm.untracked && !untracked {
+			vm.mapState()
+		}
+	}
+
+	vm.full, vm.changed = vm.untracked || vm.cache == nil, vm.dirty
+	vm.untracked, vm.dirty = false, make(map[string]struct{}, 0)
+	node, err := vm.execute(vm.state)
+	if err == nil && vm.comp.isSub {
 		var ok bool
 		if node, ok = firstElement(node); !ok {
-			must(fmt.Errorf("failed to find first element from node: %s", node.Data))
+			err = fmt.Errorf("failed to find first element from template: %s", vm.comp.tmpl)
 		}
 	}
-	vm.vnode.render(node, vm.subs)
+	if err != nil {
+		vm.subs.rewind()
+		vm.slotSubs.rewind()
+		vm.handleError(err)
+		return
+	}
+	vm.subs.reset()
+	vm.slotSubs.reset()
+	vm.vnode.render(node, vm)
 	vm.subs.reset()
+	vm.slotSubs.reset()
+	if vm.mounted {
+		vm.subs.mount()
+		vm.slotSubs.mount()
+		vm.callHook(updated)
+	}
 }
 
-// mapData creates a map of state from data, props and computed.
+// mapState maps data, props and computed to state.
+// Data is mapped when mutated without Set, otherwise Set maps the fields as needed.
 func (vm *ViewModel) mapState() {
+	if vm.untracked {
+		vm.mapData()
+	}
+	vm.mapProps()
+	vm.mapComputed()
+}
+
+// mapData maps the fields of data to state.
+func (vm *ViewModel) mapData() {
 	elem := reflect.Indirect(vm.data)
 	typ := elem.Type()
 	n := elem.NumField()
-	vm.state = make(map[string]interface{}, n)
 	for i := 0; i < n; i++ {
 		field := elem.Field(i)
 		if field.CanInterface() {
@@ -34,8 +83,6 @@ func (vm *ViewModel) mapState() {
 			vm.mapField(name, value)
 		}
 	}
-	vm.mapProps()
-	vm.mapComputed()
 }
 
 // mapProps maps props to state.
@@ -46,31 +93,21 @@ func (vm *ViewModel) mapProps() {
 }
 
 // mapComputed maps computed to state.
+// Computed are calculated again once invalidated, in order of use by other computed.
 func (vm *ViewModel) mapComputed() {
 	for computed, function := range vm.comp.computed {
-		if _, ok := vm.state[computed]; !ok {
-			value := vm.compute(function)
-			vm.mapField(computed, value)
+		if !vm.shadowed(computed) {
+			vm.getComputed(computed, function)
 		}
 	}
 }
 
 // mapField maps a field to state.
-// Watchers are called on field changes.
+// Changed fields are marked for the next render.
 func (vm *ViewModel) mapField(field string, value interface{}) {
 	oldField, ok := vm.state[field]
 	vm.state[field] = value
-	if !ok {
-		return
-	}
-
-	if watcher, ok := vm.comp.watchers[field]; ok {
-		newVal := reflect.ValueOf(value)
-		oldVal := reflect.ValueOf(oldField)
-		if reflect.DeepEqual(newVal, oldVal) {
-			return
-		}
-		values := append([]reflect.Value{reflect.ValueOf(vm)}, newVal, oldVal)
-		watcher.Call(values)
+	if ok && !reflect.DeepEqual(value, oldField) {
+		vm.markField(field)
 	}
 }
@@ -0,0 +1,128 @@
+package vue
+
+import (
+	"sort"
+	"sync"
+	"syscall/js"
+)
+
+// scheduler is the update loop which serializes the mutations and renders of view models.
+// Updates are scheduled on the next animation frame which calls the posted functions,
+// renders the queued view models, then calls the next tick functions.
+// Functions may be posted and view models queued from any goroutine.
+type scheduler struct {
+	mu      sync.Mutex
+	posted  []func()
+	queue   []*ViewModel
+	queued  map[*ViewModel]struct{}
+	ticks   []func()
+	pending bool
+	flushFn js.Func
+}
+
+// updates is the update loop of all view models.
+var updates = &scheduler{queued: make(map[*ViewModel]struct{}, 0)}
+
+func init() {
+	updates.flushFn = js.FuncOf(func(js.Value, []js.Value) interface{} {
+		updates.flush()
+		return nil
+	})
+}
+
+// post posts the function to be called by the next update.
+func (sched *scheduler) post(function func()) {
+	sched.mu.Lock()
+	defer sched.mu.Unlock()
+	sched.posted = append(sched.posted, function)
+	sched.schedule()
+}
+
+// push queues the view model to be rendered by the next update.
+func (sched *scheduler) push(vm *ViewModel) {
+	sched.mu.Lock()
+	defer sched.mu.Unlock()
+	if _, ok := sched.queued[vm]; !ok {
+		sched.queued[vm] = struct{}{}
+		sched.queue = append(sched.queue, vm)
+	}
+	sched.schedule()
+}
+
+// nextTick queues the function to be called after the next update.
+func (sched *scheduler) nextTick(function func()) {
+	sched.mu.Lock()
+	defer sched.mu.Unlock()
+	sched.ticks = append(sched.ticks, function)
+	sched.schedule()
+}
+
+// schedule schedules the next update unless it is pending.
+// Without animation frames, e.g. in workers, the update is scheduled by a timeout.
+// The lock is required to be held.
+func (sched *scheduler) schedule() {
+	if sched.pending {
+		return
+	}
+	sched.pending = true
+	if raf := js.Global().Get("requestAnimationFrame"); raf.Truthy() {
+		js.Global().Call("requestAnimationFrame", sched.flushFn)
+	} else {
+		js.Global().Call("setTimeout", sched.flushFn, 0)
+	}
+}
+
+// flush calls the posted functions, renders the queued view models, parents before subcomponents,
+// then calls the next tick functions.
+// Renders queued while rendering are scheduled by the next update.
+func (sched *scheduler) flush() {
+	sched.mu.Lock()
+	posted := sched.posted
+	sched.posted, sched.pending = nil, false
+	sched.mu.Unlock()
+
+	for _, function := range posted {
+		function()
+	}
+
+	sched.mu.Lock()
+	queue, ticks := sched.queue, sched.ticks
+	sched.queue, sched.ticks = nil, nil
+	sched.queued = make(map[*ViewModel]struct{}, 0)
+	sched.mu.Unlock()
+
+	sort.SliceStable(queue, func(i, j int) bool {
+		return queue[i].depth() < queue[j].depth()
+	})
+	for _, vm := range queue {
+		if !vm.destroyed {
+			vm.render()
+		}
+	}
+	for _, tick := range ticks {
+		tick()
+	}
+}
+
+// schedule schedules the view model to be rendered by the next update.
+func (vm *ViewModel) schedule() {
+	updates.push(vm)
+}
+
+// mutated schedules a render after data may have been mutated, e.g. by a method.
+// Without fields set, the data may be mutated otherwise.
+func (vm *ViewModel) mutated() {
+	if len(vm.dirty) == 0 {
+		vm.markData()
+	}
+	vm.schedule()
+}
+
+// depth returns the number of ancestors of the view model.
+func (vm *ViewModel) depth() int {
+	depth := 0
+	for parent := vm.parent; parent != nil; parent = parent.parent {
+		depth++
+	}
+	return depth
+}
@@ -0,0 +1,77 @@
+package vue
+
+import (
+	"fmt"
+)
+
+// scope is a chain of data for executing templates.
+// Loop variables are declared in child scopes which shadow the names of parent scopes.
+// The path identifies the iteration of the scope by the indexes of the enclosing loops, e.g. /0/2.
+type scope struct {
+	parent *scope
+	data   map[string]interface{}
+	path   string
+	origin *origin
+}
+
+// origin is the iterated value of a loop variable, e.g. Item of Items at the key.
+type origin struct {
+	name string
+	src  expr
+	key  interface{}
+}
+
+// newScope creates a new root scope from the data.
+func newScope(data map[string]interface{}) *scope {
+	return &scope{data: data}
+}
+
+// child creates a new child scope with the data.
+func (scope *scope) child(data map[string]interface{}) *scope {
+	child := newScope(data)
+	child.parent, child.path = scope, scope.path
+	return child
+}
+
+// iteration creates a new child scope with the data of the loop item at the index.
+// The origin of the loop variable resolves the item for the vue model attribute.
+func (scope *scope) iteration(data map[string]interface{}, index int, origin *origin) *scope {
+	child := scope.child(data)
+	child.path = fmt.Sprintf("%s/%d", scope.path, index)
+	child.origin = origin
+	return child
+}
+
+// lookup returns the origin and scope of the nearest declaration of the name if it is a loop variable.
+// Returns false if the name is not a loop variable, e.g. a data field.
+func (scope *scope) lookup(name string) (*origin, *scope, bool) {
+	for s := scope; s != nil; s = s.parent {
+		if _, ok := s.data[name]; ok {
+			if s.origin != nil && s.origin.name == name {
+				return s.origin, s, true
+			}
+			return nil, nil, false
+		}
+	}
+	return nil, nil, false
+}
+
+// get gets the value of the name from the nearest scope.
+// Returns false if the name is not declared.
+func (scope *scope) get(name string) (interface{}, bool) {
+	for s := scope; s != nil; s = s.parent {
+		if value, ok := s.data[name]; ok {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// contexts returns the data of the chain ordered from the nearest scope, e.g. for mustache.
+func (scope *scope) contexts() []interface{} {
+	var contexts []interface{}
+	for s := scope; s != nil; s = s.parent {
+		contexts = append(contexts, s.data)
+	}
+	return contexts
+}
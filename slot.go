@@ -0,0 +1,230 @@
+package vue
+
+import (
+	"fmt"
+	"golang.org/x/net/html"
+)
+
+// defaultSlot is the name of the slot without a name.
+const defaultSlot = "default"
+
+// slotContent is the compiled content of a subcomponent element for a slot.
+// The props variable declares the slot props of scoped slots, e.g. v-slot:item="props".
+type slotContent struct {
+	tmpl  *tmplNode
+	props string
+}
+
+// slot is the content passed to a subcomponent instance by the parent.
+// The content is executed by the parent in the scope of the subcomponent element.
+type slot struct {
+	content *slotContent
+	vm      *ViewModel
+	scope   *scope
+}
+
+// compileSlots distributes the children of the subcomponent element into slot contents.
+// Template elements with v-slot are named slots, other children are the default slot content.
+func (comp *Comp) compileSlots(tmpl *tmplNode, node *html.Node) error {
+	def := &slotContent{tmpl: &tmplNode{typ: html.ElementNode}}
+	if dir := tmpl.removeDir(vSlot); dir != nil {
+		if dir.part != "" && dir.part != defaultSlot {
+			err := fmt.Errorf("v-slot of a subcomponent element is not the default slot: %s", dir.part)
+			return comp.newError(err, nodeLocation(node), dir.String())
+		}
+		def.props = dir.val
+	}
+
+	slots := make(map[string]*slotContent, 0)
+	for _, child := range tmpl.children {
+		var dir *directive
+		if child.typ == html.ElementNode && child.data == "template" {
+			dir = child.removeDir(vSlot)
+		}
+		if dir == nil {
+			def.tmpl.children = append(def.tmpl.children, child)
+			continue
+		}
+		name := dir.part
+		if name == "" {
+			name = defaultSlot
+		}
+		if _, ok := slots[name]; ok {
+			err := fmt.Errorf("duplicate slot: %s", name)
+			return comp.newError(err, nodeLocation(node), dir.String())
+		}
+		slots[name] = &slotContent{tmpl: child, props: dir.val}
+	}
+	if _, ok := slots[defaultSlot]; !ok && def.tmpl.hasContent() {
+		slots[defaultSlot] = def
+	}
+	tmpl.slots, tmpl.children = slots, nil
+	return nil
+}
+
+// removeDir removes the directive of the type from the element.
+// Returns nil if the element has no such directive.
+func (tmpl *tmplNode) removeDir(typ string) *directive {
+	for i, dir := range tmpl.dirs {
+		if dir.typ == typ {
+			tmpl.dirs = append(tmpl.dirs[:i], tmpl.dirs[i+1:]...)
+			return dir
+		}
+	}
+	return nil
+}
+
+// hasContent returns true if the node has children other than whitespace.
+func (tmpl *tmplNode) hasContent() bool {
+	for _, child := range tmpl.children {
+		if !child.isSpace() {
+			return true
+		}
+	}
+	return false
+}
+
+// newSlots creates the slots of the subcomponent element executed in the scope.
+// Returns nil if the element has no slot content.
+func (vm *ViewModel) newSlots(tmpl *tmplNode, scope *scope) map[string]*slot {
+	if len(tmpl.slots) == 0 {
+		return nil
+	}
+	slots := make(map[string]*slot, len(tmpl.slots))
+	for name, content := range tmpl.slots {
+		slots[name] = &slot{content: content, vm: vm, scope: scope}
+	}
+	return slots
+}
+
+// executeSlot executes the slot element with the content passed by the parent, otherwise the fallback content.
+// The bound attributes of the slot element are passed to scoped slots as props.
+// For example: <slot name="item" v-bind:item="Item"> -> v-slot:item="props" with props.Item
+func (vm *ViewModel) executeSlot(tmpl *tmplNode, i int, scope *scope, parent *html.Node) error {
+	name := defaultSlot
+	for _, attr := range tmpl.attrs {
+		if attr.Key == "name" {
+			name = attr.Val
+		}
+	}
+	slot, ok := vm.slots[name]
+	if !ok {
+		return vm.executeChildren(tmpl, scope, parent)
+	}
+
+	props := make(map[string]interface{}, 0)
+	for _, dir := range tmpl.dirs[i:] {
+		if dir.typ != vBind {
+			continue
+		}
+		value, err := dir.expr.eval(vm, scope)
+		if err != nil {
+			return vm.newError(err, elementLocation(parent, tmpl.data), dir.String())
+		}
+		props[kebabTitle(dir.part)] = value
+	}
+	// Slots forwarded by slot content are rendered by the subcomponent rendering the content.
+	renderer := vm
+	if vm.slotting != nil {
+		renderer = vm.slotting
+	}
+	return renderer.executeSlotContent(slot, props, parent)
+}
+
+// executeSlotContent executes the slot content by the parent with the props which is appended to the html node.
+// The content is executed in a child scope to be executed again by every render of the subcomponent.
+// Subcomponents of the content are instances of the parent, kept by the subcomponent rendering the content.
+func (vm *ViewModel) executeSlotContent(slot *slot, props map[string]interface{}, node *html.Node) error {
+	data := make(map[string]interface{}, 1)
+	if slot.content.props != "" {
+		data[slot.content.props] = props
+	}
+
+	owner, subs := slot.vm, vm.slotSubs.subs(slot.vm)
+	ownerSubs, slotting, last := owner.subs, owner.slotting, node.LastChild
+	owner.subs, owner.slotting = subs, vm
+	defer func() {
+		owner.subs, owner.slotting = ownerSubs, slotting
+	}()
+	if err := owner.executeChildren(slot.content.tmpl, slot.scope.child(data), node); err != nil {
+		return err
+	}
+
+	child := node.FirstChild
+	if last != nil {
+		child = last.NextSibling
+	}
+	for ; child != nil; child = child.NextSibling {
+		vm.markSlotted(child, subs)
+	}
+	return nil
+}
+
+// markSlotted recursively marks the subcomponent elements of the executed slot content with the subcomponents.
+// Elements of forwarded slots are already marked.
+func (vm *ViewModel) markSlotted(node *html.Node, subs subs) {
+	if node.Type != html.ElementNode {
+		return
+	}
+	if _, ok := vm.slotted[node]; ok {
+		return
+	}
+	if _, ok := subs[node.Data]; ok {
+		vm.slotted[node] = subs
+		return
+	}
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		vm.markSlotted(child, subs)
+	}
+}
+
+// subNode retrieves a virtual node of the subcomponent element, either of slot content or of the template.
+// Returns false if the element is not a subcomponent.
+func (vm *ViewModel) subNode(node *html.Node) (*vnode, bool) {
+	if subs, ok := vm.slotted[node]; ok {
+		return subs.vnode(node)
+	}
+	return vm.subs.vnode(node)
+}
+
+// slotSubs maps the parents to the subcomponents of their slot contents.
+type slotSubs map[*ViewModel]subs
+
+// subs returns the subcomponents of the slot contents of the parent.
+// The subcomponents are created once by the first slot content of the parent.
+func (slotSubs slotSubs) subs(parent *ViewModel) subs {
+	subs, ok := slotSubs[parent]
+	if !ok {
+		subs = newSubs(parent.comp.subs)
+		slotSubs[parent] = subs
+	}
+	return subs
+}
+
+// reset resets the subcomponents of all slot contents.
+func (slotSubs slotSubs) reset() {
+	for _, subs := range slotSubs {
+		subs.reset()
+	}
+}
+
+// rewind rewinds the subcomponents of all slot contents, e.g. after failed execution.
+func (slotSubs slotSubs) rewind() {
+	for _, subs := range slotSubs {
+		subs.rewind()
+	}
+}
+
+// mount mounts the subcomponent instances of all slot contents.
+func (slotSubs slotSubs) mount() {
+	for _, subs := range slotSubs {
+		subs.mount()
+	}
+}
+
+// destroy destroys the subcomponent instances of all slot contents.
+func (slotSubs slotSubs) destroy() {
+	for _, subs := range slotSubs {
+		subs.destroy()
+	}
+}
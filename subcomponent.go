@@ -1,5 +1,10 @@
 package vue
 
+import (
+	"golang.org/x/net/html"
+	"strings"
+)
+
 // subs maps elements to subcomponents
 type subs map[string]*sub
 
@@ -7,13 +12,26 @@ type subs map[string]*sub
 type sub struct {
 	comp      *Comp
 	index     int
-	instances map[int]*instance
+	props     map[string]interface{}
+	model     *model
+	listeners map[string][]*handler
+	instances map[instanceKey]*instance
+	used      map[instanceKey]struct{}
+	nodes     map[*html.Node]instanceKey
+}
+
+// instanceKey identifies an instance by key within the list of the element, otherwise by index.
+// The list is the element of the template in the enclosing iteration, like keyed nodes are matched by parent.
+type instanceKey struct {
+	tmpl  *tmplNode
+	path  string
+	key   string
+	index int
 }
 
 // instance contains a view model with props.
 type instance struct {
-	props map[string]interface{}
-	vm    *ViewModel
+	vm *ViewModel
 }
 
 // newSubs creates a new map of subcomponents.
@@ -27,88 +45,167 @@ func newSubs(comps map[string]*Comp) subs {
 
 // newSub creates a new subcomponent.
 func newSub(comp *Comp) *sub {
-	instances := make(map[int]*instance, 0)
-	return &sub{comp: comp, instances: instances}
+	instances := make(map[instanceKey]*instance, 0)
+	used := make(map[instanceKey]struct{}, 0)
+	nodes := make(map[*html.Node]instanceKey, 0)
+	return &sub{comp: comp, instances: instances, used: used, nodes: nodes}
 }
 
-// putProp puts the props in the subcomponent.
+// putProp puts the prop of the attribute in the subcomponent.
 // Returns false if the element is not a subcomponent
 // or the subcomponent is not expecting the prop.
-func (subs subs) putProp(element, field string, data interface{}) bool {
+func (subs subs) putProp(element, attr string, data interface{}) bool {
 	sub, ok := subs[element]
 	if !ok {
 		return false
 	}
-	return sub.putProp(field, data)
+	return sub.putProp(attr, data)
 }
 
-// putProp puts the props in the instance.
+// putProp puts the prop of the attribute for the next instance.
 // Returns false if the subcomponent is not expecting the prop.
-func (sub *sub) putProp(field string, data interface{}) bool {
+func (sub *sub) putProp(attr string, data interface{}) bool {
+	field := sub.comp.propName(attr)
 	if _, ok := sub.comp.props[field]; !ok {
 		return false
 	}
 
-	if inst, ok := sub.instances[sub.index]; ok {
-		if inst.props == nil {
-			inst.props = map[string]interface{}{field: data}
-		} else {
-			inst.props[field] = data
+	if sub.props == nil {
+		sub.props = map[string]interface{}{field: data}
+	} else {
+		sub.props[field] = data
+	}
+	return true
+}
+
+// putAttrs puts the static attributes which are props in the subcomponent as literal props.
+// The other attributes are returned, e.g. for the root element of the subcomponent.
+func (subs subs) putAttrs(element string, attrs []html.Attribute) []html.Attribute {
+	sub, ok := subs[element]
+	if !ok {
+		return attrs
+	}
+	var rest []html.Attribute
+	for _, attr := range attrs {
+		if attr.Key == keyAttr || !sub.putProp(attr.Key, attr.Val) {
+			rest = append(rest, attr)
 		}
+	}
+	return rest
+}
+
+// putModel puts the model with the value prop for the next instance.
+// Returns false if the element is not a subcomponent.
+func (subs subs) putModel(element string, model *model, value interface{}) bool {
+	sub, ok := subs[element]
+	if !ok {
+		return false
+	}
+	if sub.props == nil {
+		sub.props = map[string]interface{}{modelProp: value}
 	} else {
-		sub.instances[sub.index] = &instance{props: map[string]interface{}{field: data}}
+		sub.props[modelProp] = value
 	}
+	sub.model = model
 	return true
 }
 
-// newInstance creates a new instance of the subcomponent with props.
-// // Returns false if the element is not a subcomponent.
-func (subs subs) newInstance(element string, bus *bus) bool {
+// putListener puts the handler of the event for the next instance.
+// Returns false if the element is not a subcomponent or the subcomponent declares emits without the event.
+func (subs subs) putListener(element, event string, on *handler) bool {
 	sub, ok := subs[element]
 	if !ok {
 		return false
 	}
-	return sub.newInstance(bus)
+	if _, ok := sub.comp.emits[event]; !ok && len(sub.comp.emits) > 0 {
+		return false
+	}
+	if sub.listeners == nil {
+		sub.listeners = make(map[string][]*handler, 0)
+	}
+	sub.listeners[event] = append(sub.listeners[event], on)
+	return true
+}
+
+// newInstance creates a new instance of the subcomponent element of the template executed in the scope.
+// Returns false if the element is not a subcomponent.
+func (subs subs) newInstance(node *html.Node, tmpl *tmplNode, scope *scope, parent *ViewModel, slots map[string]*slot) bool {
+	sub, ok := subs[node.Data]
+	if !ok {
+		return false
+	}
+	sub.newInstance(node, tmpl, scope, parent, slots)
+	return true
 }
 
-// newInstance creates a new instance of the subcomponent with props.
-func (sub *sub) newInstance(bus *bus) bool {
-	if inst, ok := sub.instances[sub.index]; ok {
-		if inst.vm == nil {
-			inst.vm = newViewModel(sub.comp, bus, inst.props)
-		} else {
-			inst.vm.props = inst.props
-			inst.vm.render()
+// newInstance creates a new instance of the subcomponent element with props and slots.
+// An existing instance with the same key is rendered instead.
+func (sub *sub) newInstance(node *html.Node, tmpl *tmplNode, scope *scope, parent *ViewModel, slots map[string]*slot) {
+	id := sub.instanceKey(tmpl, scope, nodeKey(node.Attr))
+	sub.nodes[node] = id
+	props, model, listeners := sub.props, sub.model, sub.listeners
+	sub.props, sub.model, sub.listeners = nil, nil, nil
+
+	if inst, ok := sub.instances[id]; ok {
+		inst.vm.props = inst.vm.validateProps(props)
+		inst.vm.model = model
+		inst.vm.slots = slots
+		inst.vm.listeners = listeners
+		// Props may be mutated without Set by a full render of the parent.
+		if parent.full {
+			inst.vm.markData()
 		}
+		inst.vm.render()
 	} else {
-		vm := newViewModel(sub.comp, bus, nil)
-		sub.instances[sub.index] = &instance{vm: vm}
+		vm := newViewModel(sub.comp, parent, props, slots)
+		vm.model = model
+		vm.listeners = listeners
+		sub.instances[id] = &instance{vm: vm}
 	}
+	sub.used[id] = struct{}{}
 	sub.index++
-	return true
 }
 
-// vnode retrieves a virtual node of the subcomponent.
-// // Returns false if the element is not a subcomponent.
-func (subs subs) vnode(element string) (*vnode, bool) {
-	sub, ok := subs[element]
+// vnode retrieves a virtual node of the executed subcomponent element.
+// Returns false if the element is not a subcomponent.
+func (subs subs) vnode(node *html.Node) (*vnode, bool) {
+	sub, ok := subs[node.Data]
 	if !ok {
 		return nil, false
 	}
-	vnode, ok := sub.vnode()
+	vnode, ok := sub.vnode(node)
 	return vnode, ok
 }
 
-// vnode retrieves a virtual node of the subcomponent.
-func (sub *sub) vnode() (*vnode, bool) {
-	inst, ok := sub.instances[sub.index]
+// vnode retrieves a virtual node of the instance executed by the element.
+func (sub *sub) vnode(node *html.Node) (*vnode, bool) {
+	id, ok := sub.nodes[node]
 	if !ok {
 		return nil, false
 	}
-	sub.index++
+	delete(sub.nodes, node)
+	inst, ok := sub.instances[id]
+	if !ok {
+		return nil, false
+	}
+	sub.used[id] = struct{}{}
 	return inst.vm.vnode, true
 }
 
+// instanceKey returns the key of the current instance of the template element executed in the scope.
+// Keyed instances are identified within the list of the element, which excludes the iteration of its own loop.
+// Instances without a key are identified by index.
+func (sub *sub) instanceKey(tmpl *tmplNode, scope *scope, key string) instanceKey {
+	if key == "" {
+		return instanceKey{index: sub.index}
+	}
+	path := scope.path
+	if i := strings.LastIndex(path, "/"); i >= 0 && tmpl.findDir(vFor) != nil {
+		path = path[:i]
+	}
+	return instanceKey{tmpl: tmpl, path: path, key: key, index: -1}
+}
+
 // reset resets all subcomponents.
 func (subs subs) reset() {
 	for _, sub := range subs {
@@ -116,11 +213,43 @@ func (subs subs) reset() {
 	}
 }
 
-// reset cleans up and unmounts unused subcomponent instances.
+// rewind rewinds all subcomponents without cleaning up instances, e.g. after failed execution.
+func (subs subs) rewind() {
+	for _, sub := range subs {
+		sub.props, sub.model, sub.listeners = nil, nil, nil
+		sub.used = make(map[instanceKey]struct{}, len(sub.instances))
+		sub.nodes = make(map[*html.Node]instanceKey, 0)
+		sub.index = 0
+	}
+}
+
+// reset cleans up and unmounts instances which were not used since the last reset.
 func (sub *sub) reset() {
-	for i := sub.index; i < len(sub.instances); {
-		sub.instances[i].vm.release()
-		delete(sub.instances, i)
+	for id, inst := range sub.instances {
+		if _, ok := sub.used[id]; !ok {
+			inst.vm.destroy()
+			delete(sub.instances, id)
+		}
 	}
+	sub.used = make(map[instanceKey]struct{}, len(sub.instances))
 	sub.index = 0
 }
+
+// mount mounts all subcomponent instances.
+func (subs subs) mount() {
+	for _, sub := range subs {
+		for _, inst := range sub.instances {
+			inst.vm.mount()
+		}
+	}
+}
+
+// destroy destroys all subcomponent instances.
+func (subs subs) destroy() {
+	for _, sub := range subs {
+		for id, inst := range sub.instances {
+			inst.vm.destroy()
+			delete(sub.instances, id)
+		}
+	}
+}
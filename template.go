@@ -3,7 +3,6 @@ package vue
 import (
 	"bytes"
 	"fmt"
-	"github.com/cbroglie/mustache"
 	"golang.org/x/net/html"
 	"golang.org/x/net/html/atom"
 	"io"
@@ -12,259 +11,381 @@ import (
 )
 
 const (
-	v      = "v-"
-	vBind  = "v-bind"
-	vFor   = "v-for"
-	vHtml  = "v-html"
-	vIf    = "v-if"
-	vModel = "v-model"
-	vOn    = "v-on"
+	v       = "v-"
+	vBind   = "v-bind"
+	vElse   = "v-else"
+	vElseIf = "v-else-if"
+	vFor    = "v-for"
+	vHtml   = "v-html"
+	vIf     = "v-if"
+	vModel  = "v-model"
+	vOn     = "v-on"
+	vShow   = "v-show"
+	vSlot   = "v-slot"
 )
 
-var attrOrder = []string{vFor, vIf, vModel, vOn, vBind, vHtml}
+var attrOrder = []string{vFor, vIf, vElseIf, vElse, vModel, vOn, vBind, vShow, vHtml}
 
-// execute executes the template with the given data to be rendered.
-func (vm *ViewModel) execute(data map[string]interface{}) *html.Node {
-	node := parseNode(vm.comp.tmpl)
-
-	vm.executeElement(node, data)
-	executeText(node, data)
+// execute executes the compiled template with the given data to be rendered.
+// A panic, e.g. of a method, is returned as an error like failed execution.
+func (vm *ViewModel) execute(data map[string]interface{}) (_ *html.Node, err error) {
+	if vm.comp.err != nil {
+		return nil, vm.comp.err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			vm.cache = nil
+			err = vm.newError(recovered(r), "", "render")
+		}
+	}()
 
-	return node
+	// The node returned is a placeholder, not to be rendered.
+	node := &html.Node{Type: html.ElementNode}
+	vm.handlers = make(map[int]*handler, len(vm.handlers))
+	vm.slotted = make(map[*html.Node]subs, len(vm.slotted))
+	vm.rendered = make(map[*tmplNode]*tracked, len(vm.cache))
+	if err := vm.executeChildren(vm.comp.root, newScope(data), node); err != nil {
+		vm.cache = nil
+		return nil, err
+	}
+	vm.cache = vm.rendered
+	return node, nil
 }
 
-// executeElement recursively traverses the html node and templates the elements.
-// The next node is always returned which allows execution to jump around as needed.
-func (vm *ViewModel) executeElement(node *html.Node, data map[string]interface{}) *html.Node {
-	// Leave the text nodes to be executed.
-	if node.Type != html.ElementNode {
-		return node.NextSibling
-	}
-
-	// Order attributes before execution.
-	orderAttrs(node)
-
-	// Execute attributes.
-	for i := 0; i < len(node.Attr); i++ {
-		attr := node.Attr[i]
-		if strings.HasPrefix(attr.Key, v) {
-			deleteAttr(node, i)
-			i--
-			next, modified := vm.executeAttr(node, attr, data)
-			// The current node is not longer valid in favor of the next node.
-			if modified {
-				return next
+// executeChildren executes the children of the template node which are appended to the html node.
+func (vm *ViewModel) executeChildren(tmpl *tmplNode, scope *scope, node *html.Node) error {
+	for _, child := range tmpl.children {
+		if child.typ == html.TextNode {
+			if err := vm.executeText(child, scope, node); err != nil {
+				return vm.newError(err, nodeLocation(node), "{{ }}")
 			}
+			continue
+		}
+		if err := vm.executeElement(child, 0, scope, node); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// executeText executes the text with the data of the scope which is appended to the html node.
+func (vm *ViewModel) executeText(tmpl *tmplNode, scope *scope, node *html.Node) error {
+	data := tmpl.data
+	if tmpl.text != nil {
+		for _, name := range tmpl.names {
+			vm.track(name)
+		}
+		var err error
+		if data, err = tmpl.text.Render(scope.contexts()...); err != nil {
+			return err
+		}
+	}
+	node.AppendChild(&html.Node{Type: html.TextNode, Data: data})
+	return nil
+}
+
+// executeElement executes the template element from the given directive index.
+// Elements in the root scope without subcomponents are tracked to be reused by renders.
+func (vm *ViewModel) executeElement(tmpl *tmplNode, i int, scope *scope, parent *html.Node) error {
+	if i == 0 && scope.parent == nil && !tmpl.hasSub {
+		return vm.executeTracked(tmpl, scope, parent)
+	}
+	return vm.executeDirectives(tmpl, i, scope, parent)
+}
+
+// executeDirectives executes the directives of the template element from the given index.
+// The element is appended to the parent with its executed children.
+// The vue for and if directives execute the following directives for each item or the kept element.
+func (vm *ViewModel) executeDirectives(tmpl *tmplNode, i int, scope *scope, parent *html.Node) error {
+	if i < len(tmpl.dirs) && tmpl.dirs[i].typ == vFor {
+		return vm.executeFor(tmpl, i, scope, parent)
+	}
+	if i < len(tmpl.dirs) && tmpl.dirs[i].typ == vIf {
+		kept, err := vm.executeIf(tmpl, tmpl.dirs[i].expr, scope)
+		if err != nil {
+			return vm.newError(err, elementLocation(parent, tmpl.data), tmpl.dirs[i].String())
+		}
+		switch kept {
+		case tmpl:
+			i++
+		case nil:
+			return nil
+		default:
+			return vm.executeElement(kept, 0, scope, parent)
+		}
+	}
+	if tmpl.data == "slot" {
+		return vm.executeSlot(tmpl, i, scope, parent)
+	}
+
+	node := &html.Node{
+		Type:     html.ElementNode,
+		Data:     tmpl.data,
+		DataAtom: tmpl.atom,
+		Attr:     append([]html.Attribute(nil), tmpl.attrs...),
+	}
+	parent.AppendChild(node)
+	// Static attributes of subcomponents are passed as literal props, bound props take precedence.
+	node.Attr = vm.subs.putAttrs(node.Data, node.Attr)
+
+	// Execute attributes, the html attribute replaces the children.
+	var inner *directive
+	for _, dir := range tmpl.dirs[i:] {
+		if dir.typ == vHtml {
+			inner = dir
+			continue
+		}
+		if err := vm.executeAttr(node, dir, scope); err != nil {
+			return vm.newError(err, nodeLocation(node), dir.String())
 		}
 	}
 
-	// Execute subcomponent.
-	if vm.subs.newInstance(node.Data, vm.bus) {
-		return node.NextSibling
+	// Execute subcomponent with the slot content.
+	if vm.subs.newInstance(node, tmpl, scope, vm, vm.newSlots(tmpl, scope)) {
+		return nil
 	}
 
 	// Execute children.
-	for child := node.FirstChild; child != nil; {
-		child = vm.executeElement(child, data)
+	if inner != nil {
+		if err := vm.executeAttrHtml(node, inner, scope); err != nil {
+			return vm.newError(err, nodeLocation(node), inner.String())
+		}
+	} else if err := vm.executeChildren(tmpl, scope, node); err != nil {
+		return err
 	}
 
-	return node.NextSibling
+	// Select options of the vue model attribute.
+	if value, ok := vm.selects[node]; ok {
+		delete(vm.selects, node)
+		selectOptions(node, value)
+	}
+	return nil
 }
 
-// executeText recursively executes the text node.
-func executeText(node *html.Node, data map[string]interface{}) {
-	switch node.Type {
-	case html.TextNode:
-		if strings.TrimSpace(node.Data) == "" {
-			return
+// executeFor executes the vue for directive of the template element at the index.
+// The element is repeated for each item with the loop variables declared in a child scope.
+func (vm *ViewModel) executeFor(tmpl *tmplNode, i int, scope *scope, parent *html.Node) error {
+	dir := tmpl.dirs[i]
+	value, err := dir.expr.eval(vm, scope)
+	var items []loopItem
+	if err == nil {
+		items, err = loopItems(value)
+	}
+	if err != nil {
+		return vm.newError(err, elementLocation(parent, tmpl.data), dir.String())
+	}
+
+	for _, item := range items {
+		origin := &origin{name: dir.loop.value, src: dir.expr, key: item.key}
+		if err := vm.executeElement(tmpl, i+1, scope.iteration(dir.loop.vars(item), item.index, origin), parent); err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		var err error
-		node.Data, err = mustache.Render(node.Data, data)
-		must(err)
-	case html.ElementNode:
-		for child := node.FirstChild; child != nil; child = child.NextSibling {
-			executeText(child, data)
+// executeIf executes the vue if directive with the conditional chain of the template element.
+// The first element of the chain with a true condition is returned, otherwise nil.
+func (vm *ViewModel) executeIf(tmpl *tmplNode, x expr, scope *scope) (*tmplNode, error) {
+	chain := append([]condition{{node: tmpl, expr: x}}, tmpl.chain...)
+	for _, cond := range chain {
+		if cond.expr == nil {
+			return cond.node, nil
+		}
+		value, err := cond.expr.eval(vm, scope)
+		if err != nil {
+			return nil, err
+		}
+		if truthy(value) {
+			return cond.node, nil
 		}
 	}
+	return nil, nil
 }
 
 // executeAttr executes the given vue attribute.
-// The next node will be executed next if the html was modified unless it is nil.
-func (vm *ViewModel) executeAttr(node *html.Node, attr html.Attribute, data map[string]interface{}) (*html.Node, bool) {
-	vals := strings.Split(attr.Key, ":")
-	typ, part := vals[0], ""
-	if len(vals) > 1 {
-		part = vals[1]
-	}
-	var next *html.Node
-	var modified bool
-	switch typ {
+func (vm *ViewModel) executeAttr(node *html.Node, dir *directive, scope *scope) error {
+	switch dir.typ {
 	case vBind:
-		vm.executeAttrBind(node, part, attr.Val, data)
-	case vFor:
-		next, modified = vm.executeAttrFor(node, attr.Val, data)
-	case vHtml:
-		executeAttrHtml(node, attr.Val, data)
-	case vIf:
-		next, modified = vm.executeAttrIf(node, attr.Val, data)
+		return vm.executeAttrBind(node, dir, scope)
 	case vModel:
-		vm.executeAttrModel(node, attr.Val, data)
+		return vm.executeAttrModel(node, dir, scope)
 	case vOn:
-		vm.executeAttrOn(node, part, attr.Val)
+		return vm.executeAttrOn(node, dir, scope)
+	case vShow:
+		return vm.executeAttrShow(node, dir, scope)
 	default:
-		must(fmt.Errorf("unknown vue attribute: %v", typ))
+		return fmt.Errorf("unknown vue attribute: %v", dir.typ)
 	}
-	return next, modified
 }
 
 // executeAttrBind executes the vue bind attribute.
-func (vm *ViewModel) executeAttrBind(node *html.Node, key, field string, data map[string]interface{}) {
-	value, ok := data[field]
-	if !ok {
-		must(fmt.Errorf("unknown data field: %s", field))
+func (vm *ViewModel) executeAttrBind(node *html.Node, dir *directive, scope *scope) error {
+	value, err := dir.expr.eval(vm, scope)
+	if err != nil {
+		return err
 	}
 
-	prop := strings.Title(key)
-	if ok := vm.subs.putProp(node.Data, prop, value); ok {
-		return
+	key := dir.part
+	if ok := vm.subs.putProp(node.Data, key, value); ok {
+		return nil
 	}
 
 	if key == "class" {
-		class := formatAttrClass(value)
+		class, err := formatAttrClass(value)
+		if err != nil {
+			return err
+		}
 		node.Attr = append(node.Attr, html.Attribute{Key: key, Val: class})
-		return
+		return nil
 	}
 
 	if key == "style" {
-		style := formatAttrStyle(value)
-		node.Attr = append(node.Attr, html.Attribute{Key: key, Val: style})
-		return
+		style, err := formatAttrStyle(value)
+		if err != nil {
+			return err
+		}
+		mergeAttrStyle(node, style)
+		return nil
 	}
 
 	// Remove attribute if bound to a false value of type bool.
 	if val, ok := value.(bool); ok && !val {
-		return
+		return nil
 	}
 
 	val := fmt.Sprintf("%v", value)
 	node.Attr = append(node.Attr, html.Attribute{Key: key, Val: val})
+	return nil
 }
 
-// executeAttrFor executes the vue for attribute.
-func (vm *ViewModel) executeAttrFor(node *html.Node, value string, data map[string]interface{}) (*html.Node, bool) {
-	vals := strings.Split(value, "in")
-	name := bytes.TrimSpace([]byte(vals[0]))
-	field := strings.TrimSpace(vals[1])
-
-	slice, ok := data[field]
+// executeAttrHtml executes the vue html attribute.
+func (vm *ViewModel) executeAttrHtml(node *html.Node, dir *directive, scope *scope) error {
+	value, err := dir.expr.eval(vm, scope)
+	if err != nil {
+		return err
+	}
+	html, ok := value.(string)
 	if !ok {
-		must(fmt.Errorf("slice not found for field: %s", field))
+		return fmt.Errorf("v-html value is not of type string: %T", value)
 	}
 
-	elem := bytes.NewBuffer(nil)
-	err := html.Render(elem, node)
-	must(err)
-
-	buf := bytes.NewBuffer(nil)
-	values := reflect.ValueOf(slice)
-	n := values.Len()
-	for i := 0; i < n; i++ {
-		key := fmt.Sprintf("%s%d", name, vm.index)
-		vm.index++
-
-		b := bytes.Replace(elem.Bytes(), name, []byte(key), -1)
-		_, err := buf.Write(b)
-		must(err)
-
-		data[key] = values.Index(i).Interface()
+	nodes, err := parseNodes(strings.NewReader(html))
+	if err != nil {
+		return err
 	}
-
-	nodes := parseNodes(buf)
 	for _, child := range nodes {
-		node.Parent.InsertBefore(child, node)
+		node.AppendChild(child)
 	}
-	node.Parent.RemoveChild(node)
-	// The first child is the next node to execute.
-	return nodes[0], true
+	return nil
 }
 
-// executeAttrHtml executes the vue html attribute.
-func executeAttrHtml(node *html.Node, field string, data map[string]interface{}) {
-	value, ok := data[field]
-	if !ok {
-		must(fmt.Errorf("unknown data field: %s", field))
+// executeAttrModel executes the vue model attribute with optional modifiers.
+// For example: .lazy updates on change, .trim trims and .number parses the input.
+func (vm *ViewModel) executeAttrModel(node *html.Node, dir *directive, scope *scope) error {
+	value, err := dir.expr.eval(vm, scope)
+	if err != nil {
+		return err
 	}
-	html, ok := value.(string)
-	if !ok {
-		must(fmt.Errorf("data field is not of type string: %T", field))
+	mods := dir.part
+	model := &model{vm: vm, field: dir.val, expr: dir.expr, scope: scope, modSet: modSet(strings.Split(mods, "."))}
+	if vm.subs.putModel(node.Data, model, value) {
+		return nil
 	}
 
-	nodes := parseNodes(strings.NewReader(html))
-	for _, child := range nodes {
-		node.AppendChild(child)
+	typ := modelEvent(node, mods)
+	id := vm.register(&handler{scope: scope, dir: dir, model: model})
+	node.Attr = append(node.Attr, html.Attribute{Key: modelAttr + typ + mods, Val: vm.ref(id)})
+
+	switch input := inputType(node); {
+	case node.Data == "select":
+		// Options are selected after the children are executed.
+		vm.selects[node] = value
+	case input == "checkbox", input == "radio":
+		checkOption(node, value)
+	default:
+		putAttr(node, "value", formatValue(value, input))
 	}
+
+	vm.addEventListener(listener{typ: typ, model: true}, vm.vModel)
+	return nil
 }
 
-// executeAttrIf executes the vue if attribute.
-func (vm *ViewModel) executeAttrIf(node *html.Node, field string, data map[string]interface{}) (*html.Node, bool) {
-	if value, ok := data[field]; ok {
-		if val, ok := value.(bool); ok && val {
-			return nil, false
-		}
+// executeAttrOn executes the vue on attribute.
+// The handler is registered with the scope to evaluate arguments when the event is dispatched.
+// On subcomponent elements, the handler listens to the events emitted by the instance unless native.
+func (vm *ViewModel) executeAttrOn(node *html.Node, dir *directive, scope *scope) error {
+	typ, native := nativeEvent(dir.part)
+	method, args, _ := handlerMethod(dir.expr)
+	handler := &handler{method: method, args: args, scope: scope, dir: dir}
+	if !native && vm.subs.putListener(node.Data, typ, handler) {
+		return nil
 	}
-	next := node.NextSibling
-	node.Parent.RemoveChild(node)
-	return next, true
+
+	listener := eventListener(typ)
+	id := vm.register(handler)
+	node.Attr = append(node.Attr, html.Attribute{Key: typ, Val: vm.ref(id)})
+
+	vm.addEventListener(listener, vm.vOn)
+	vm.bus.sub(listener.typ, method)
+	return nil
 }
 
-// executeAttrModel executes the vue model attribute.
-func (vm *ViewModel) executeAttrModel(node *html.Node, field string, data map[string]interface{}) {
-	typ := "input"
-	node.Attr = append(node.Attr, html.Attribute{Key: typ, Val: field})
+// register registers the handler for the render, e.g. to be found by the id of the attribute.
+// Returns the id of the handler.
+func (vm *ViewModel) register(handler *handler) int {
+	vm.handlerID++
+	id := vm.handlerID
+	vm.handlers[id] = handler
+	if vm.tracking != nil {
+		vm.tracking.handlers[id] = handler
+	}
+	return id
+}
 
-	value, ok := data[field]
-	if !ok {
-		must(fmt.Errorf("unknown data field: %s", field))
+// executeAttrShow executes the vue show attribute.
+// The element is hidden with the display style instead of being removed.
+func (vm *ViewModel) executeAttrShow(node *html.Node, dir *directive, scope *scope) error {
+	value, err := dir.expr.eval(vm, scope)
+	if err != nil {
+		return err
 	}
-	val, ok := value.(string)
-	if !ok {
-		must(fmt.Errorf("data field is not of type string: %T", field))
+	if !truthy(value) {
+		mergeAttrStyle(node, "display: none")
 	}
-	node.Attr = append(node.Attr, html.Attribute{Key: "value", Val: val})
-
-	vm.addEventListener(typ, vm.vModel)
+	return nil
 }
 
-// executeAttrOn executes the vue on attribute.
-func (vm *ViewModel) executeAttrOn(node *html.Node, typ, method string) {
-	event := strings.Split(typ, ".")[0]
-	node.Attr = append(node.Attr, html.Attribute{Key: typ, Val: method})
-
-	vm.addEventListener(event, vm.vOn)
-	vm.bus.sub(event, method)
+// eval evaluates the expression of the directive value in the scope.
+func (vm *ViewModel) eval(src string, scope *scope) (interface{}, error) {
+	x, err := vm.comp.expr(src)
+	if err != nil {
+		return nil, err
+	}
+	return x.eval(vm, scope)
 }
 
 // parseNode parses the template into an html node.
 // The node returned is a placeholder, not to be rendered.
-func parseNode(tmpl string) *html.Node {
-	nodes := parseNodes(strings.NewReader(tmpl))
+func parseNode(tmpl string) (*html.Node, error) {
+	nodes, err := parseNodes(strings.NewReader(tmpl))
+	if err != nil {
+		return nil, err
+	}
 	node := &html.Node{Type: html.ElementNode}
 	for _, child := range nodes {
 		node.AppendChild(child)
 	}
-	return node
+	return node, nil
 }
 
 // parseNodes parses the reader into html nodes.
-func parseNodes(reader io.Reader) []*html.Node {
-	nodes, err := html.ParseFragment(reader, &html.Node{
+func parseNodes(reader io.Reader) ([]*html.Node, error) {
+	return html.ParseFragment(reader, &html.Node{
 		Type:     html.ElementNode,
 		Data:     "div",
 		DataAtom: atom.Div,
 	})
-	must(err)
-	return nodes
 }
 
 // firstElement finds the first child element of a node.
@@ -278,40 +399,88 @@ func firstElement(node *html.Node) (*html.Node, bool) {
 	return nil, false
 }
 
-// orderAttrs orders the attributes of the node which orders the template execution.
-func orderAttrs(node *html.Node) {
-	n := len(node.Attr)
-	if n == 0 {
-		return
+// attrDirective returns the directive of the attribute key.
+// For example: v-on:click.enter -> v-on
+func attrDirective(key string) string {
+	if i := strings.IndexAny(key, ":."); i >= 0 {
+		return key[:i]
 	}
-	attrs := make([]html.Attribute, 0, n)
-	for _, prefix := range attrOrder {
-		for _, attr := range node.Attr {
-			if strings.HasPrefix(attr.Key, prefix) {
-				attrs = append(attrs, attr)
-			}
+	return key
+}
+
+// findAttrIndex finds the index of the attribute of the node by key.
+// Returns false if the attribute is not found.
+func findAttrIndex(node *html.Node, key string) (int, bool) {
+	for i, attr := range node.Attr {
+		if attr.Key == key {
+			return i, true
 		}
 	}
-	// Append other attributes which are not vue attributes.
-	for _, attr := range node.Attr {
-		if !strings.HasPrefix(attr.Key, v) {
-			attrs = append(attrs, attr)
-		}
+	return 0, false
+}
+
+// getAttr gets the attribute value of the node by key.
+// Returns false if the attribute is not found.
+func getAttr(node *html.Node, key string) (string, bool) {
+	if i, ok := findAttrIndex(node, key); ok {
+		return node.Attr[i].Val, true
 	}
-	node.Attr = attrs
+	return "", false
 }
 
-// deleteAttr deletes the attribute of the node at the index.
-// Attribute order is preserved.
-func deleteAttr(node *html.Node, i int) {
-	node.Attr = append(node.Attr[:i], node.Attr[i+1:]...)
+// putAttr puts the attribute value in the node, an existing attribute is replaced.
+func putAttr(node *html.Node, key, val string) {
+	if i, ok := findAttrIndex(node, key); ok {
+		node.Attr[i].Val = val
+		return
+	}
+	node.Attr = append(node.Attr, html.Attribute{Key: key, Val: val})
+}
+
+// mergeAttrStyle merges the style into the style attribute of the node.
+// For example: "color: red" + "display: none" -> "color: red; display: none"
+func mergeAttrStyle(node *html.Node, style string) {
+	if style == "" {
+		return
+	}
+	i, ok := findAttrIndex(node, "style")
+	if !ok {
+		node.Attr = append(node.Attr, html.Attribute{Key: "style", Val: style})
+		return
+	}
+	if val := strings.TrimSuffix(strings.TrimSpace(node.Attr[i].Val), ";"); val != "" {
+		style = val + "; " + style
+	}
+	node.Attr[i].Val = style
 }
 
 // formatAttrClass formats the value into a class attribute.
+// Strings are classes as is, maps of bools and structs of bools are the classes of the true entries and fields.
 // For example: { Active: true, DangerText: true } -> "active danger-text"
 // For type: struct { Active: bool `css:"active"`, DangerText: bool `css:"danger-text"` }
-func formatAttrClass(value interface{}) string {
-	elem := reflect.Indirect(reflect.ValueOf(value))
+func formatAttrClass(value interface{}) (string, error) {
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	elem := indirect(reflect.ValueOf(value))
+	switch elem.Kind() {
+	case reflect.Invalid:
+		return "", nil
+	case reflect.Map:
+		var classes []string
+		keys := elem.MapKeys()
+		sortValues(keys)
+		for _, key := range keys {
+			if val, ok := elem.MapIndex(key).Interface().(bool); ok && val {
+				classes = append(classes, fmt.Sprintf("%v", key.Interface()))
+			}
+		}
+		return strings.Join(classes, " "), nil
+	case reflect.Struct:
+	default:
+		return "", fmt.Errorf("invalid class value of type: %T", value)
+	}
+
 	typ := elem.Type()
 	n := elem.NumField()
 	buf := bytes.NewBuffer(nil)
@@ -330,14 +499,34 @@ func formatAttrClass(value interface{}) string {
 			}
 		}
 	}
-	return buf.String()
+	return buf.String(), nil
 }
 
 // formatAttrStyle formats the value into a style attribute.
+// Strings are styles as is, maps and structs are the styles of the entries and fields.
 // For example: { Color: red, FontSize: 8px } -> "color: red; font-size: 8px"
 // For type: struct { Color: string `css:"color"`, FontSize: string `css:"font-size"` }
-func formatAttrStyle(value interface{}) string {
-	elem := reflect.Indirect(reflect.ValueOf(value))
+func formatAttrStyle(value interface{}) (string, error) {
+	if s, ok := value.(string); ok {
+		return strings.TrimSuffix(strings.TrimSpace(s), ";"), nil
+	}
+	elem := indirect(reflect.ValueOf(value))
+	switch elem.Kind() {
+	case reflect.Invalid:
+		return "", nil
+	case reflect.Map:
+		var styles []string
+		keys := elem.MapKeys()
+		sortValues(keys)
+		for _, key := range keys {
+			styles = append(styles, fmt.Sprintf("%v: %v", key.Interface(), elem.MapIndex(key).Interface()))
+		}
+		return strings.Join(styles, "; "), nil
+	case reflect.Struct:
+	default:
+		return "", fmt.Errorf("invalid style value of type: %T", value)
+	}
+
 	typ := elem.Type()
 	n := elem.NumField()
 	buf := bytes.NewBuffer(nil)
@@ -354,5 +543,5 @@ func formatAttrStyle(value interface{}) string {
 			format = "; %s: %v"
 		}
 	}
-	return buf.String()
+	return buf.String(), nil
 }
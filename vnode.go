@@ -7,6 +7,9 @@ import (
 	"syscall/js"
 )
 
+// keyAttr is the attribute which identifies elements among siblings, e.g. v-bind:key.
+const keyAttr = "key"
+
 var document dom.Document
 
 type vnode struct {
@@ -15,6 +18,11 @@ type vnode struct {
 	attrs map[string]string
 	typ   html.NodeType
 	data  string
+	key   string
+	isSub bool
+
+	// src is the html node last rendered, reused html nodes are unchanged.
+	src *html.Node
 
 	node dom.Node
 }
@@ -33,14 +41,19 @@ func newNode(el string) *vnode {
 	return &vnode{attrs: node.Attributes(), node: node}
 }
 
-// newSubNode creates a virtual subcomponent node from the given template.
-func newSubNode(tmpl string) *vnode {
-	node := parseNode(tmpl)
-	var ok bool
-	if node, ok = firstElement(node); !ok {
-		must(fmt.Errorf("failed to find first element from template: %s", tmpl))
+// newSubNode creates a virtual subcomponent node from the first element of the compiled template.
+// A placeholder element is created if the template has no element.
+func newSubNode(comp *Comp) (*vnode, error) {
+	node := &html.Node{Type: html.ElementNode, Data: "div"}
+	var err error
+	if elem, ok := comp.root.firstElement(); ok {
+		node.Data = elem.data
+	} else if comp.err == nil {
+		err = fmt.Errorf("failed to find first element from template: %s", comp.tmpl)
 	}
-	return createElement(node)
+	vnode := createElement(node)
+	vnode.isSub = true
+	return vnode, err
 }
 
 // createElement creates a virtual node element without children nor attributes.
@@ -56,68 +69,135 @@ func createElement(node *html.Node) *vnode {
 }
 
 // createNode recursively creates a virtual node from the html node.
-func createNode(node *html.Node, subs subs) *vnode {
-	vnode := &vnode{typ: node.Type, data: node.Data}
+// The html node is required to be an element or text.
+func createNode(node *html.Node, vm *ViewModel) *vnode {
+	vnode := &vnode{typ: node.Type, data: node.Data, src: node}
 	switch node.Type {
 	case html.ElementNode:
-		if subNode, ok := subs.vnode(node.Data); ok {
+		vnode.key = nodeKey(node.Attr)
+		if subNode, ok := vm.subNode(node); ok {
+			subNode.key = vnode.key
 			subNode.renderAttributes(node.Attr)
 			return subNode
 		} else {
 			vnode.node = document.CreateElement(node.Data)
 			vnode.attrs = make(map[string]string, len(node.Attr))
 			for _, attr := range node.Attr {
-				vnode.setAttr(attr.Key, attr.Val)
+				if attr.Key != keyAttr {
+					vnode.setAttr(attr.Key, attr.Val)
+				}
 			}
 			for child := node.FirstChild; child != nil; child = child.NextSibling {
-				vnode.append(createNode(child, subs))
+				if child.Type == html.ElementNode || child.Type == html.TextNode {
+					vnode.append(createNode(child, vm))
+				}
 			}
 		}
 	case html.TextNode:
 		vnode.node = document.CreateTextNode(node.Data)
-	default:
-		must(fmt.Errorf("unknown node type: %v", node.Type))
 	}
 	return vnode
 }
 
 // render recursively renders the virtual node.
-func (dst *vnode) render(src *html.Node, subs subs) {
-	for dstChild, srcChild := dst.firstChild, src.FirstChild; dstChild != nil || srcChild != nil; {
-		switch {
-		case dstChild == nil:
-			dst.append(createNode(srcChild, subs))
-		case srcChild == nil:
-			dst.remove(dstChild)
-		case dstChild.typ != srcChild.Type:
-			dst.replace(createNode(srcChild, subs), dstChild)
-		default:
-			switch srcChild.Type {
-			case html.ElementNode:
-				if subNode, ok := subs.vnode(srcChild.Data); ok {
-					subNode.renderAttributes(srcChild.Attr)
-					dst.replace(subNode, dstChild)
-				} else if dstChild.data != srcChild.Data {
-					dst.replace(createNode(srcChild, subs), dstChild)
-				} else {
-					dstChild.renderAttributes(srcChild.Attr)
-					dstChild.render(srcChild, subs)
-				}
-			case html.TextNode:
-				if dstChild.data != srcChild.Data {
-					dstChild.setText(srcChild.Data)
-				}
-			default:
-				must(fmt.Errorf("unknown html node type: %v", srcChild.Type))
+// Keyed children are matched by key and moved as needed, other children are matched by position.
+func (dst *vnode) render(src *html.Node, vm *ViewModel) {
+	keyed := make(map[string]*vnode, 0)
+	for child := dst.firstChild; child != nil; child = child.nextSibling {
+		if _, ok := keyed[child.key]; !ok && child.key != "" && !child.isSub {
+			keyed[child.key] = child
+		}
+	}
+
+	// Children before the cursor are rendered, children from the cursor onward are unused.
+	cursor := dst.firstChild
+	for srcChild := src.FirstChild; srcChild != nil; srcChild = srcChild.NextSibling {
+		cursor = dst.renderChild(srcChild, cursor, keyed, vm)
+	}
+	for cursor != nil {
+		next := cursor.nextSibling
+		dst.remove(cursor)
+		cursor = next
+	}
+}
+
+// patch renders the attributes and children of the element unless the html node was last rendered.
+func (dst *vnode) patch(src *html.Node, vm *ViewModel) {
+	if dst.src == src {
+		return
+	}
+	dst.src = src
+	dst.renderAttributes(src.Attr)
+	dst.render(src, vm)
+}
+
+// renderChild renders the source child at the position of the cursor.
+// The next cursor is returned. Other nodes than elements and text are skipped.
+func (dst *vnode) renderChild(src *html.Node, cursor *vnode, keyed map[string]*vnode, vm *ViewModel) *vnode {
+	switch src.Type {
+	case html.ElementNode:
+		key := nodeKey(src.Attr)
+		if subNode, ok := vm.subNode(src); ok {
+			subNode.key = key
+			subNode.renderAttributes(src.Attr)
+			return dst.place(subNode, cursor)
+		}
+		if key != "" {
+			if old, ok := keyed[key]; ok && old.data == src.Data {
+				delete(keyed, key)
+				old.patch(src, vm)
+				return dst.place(old, cursor)
 			}
+			return dst.place(createNode(src, vm), cursor)
+		}
+		if cursor.isPositional() && cursor.typ == src.Type && cursor.data == src.Data {
+			cursor.patch(src, vm)
+			return cursor.nextSibling
 		}
-		if dstChild != nil {
-			dstChild = dstChild.nextSibling
+	case html.TextNode:
+		if cursor.isPositional() && cursor.typ == src.Type {
+			if cursor.data != src.Data {
+				cursor.setText(src.Data)
+			}
+			return cursor.nextSibling
 		}
-		if srcChild != nil {
-			srcChild = srcChild.NextSibling
+	default:
+		return cursor
+	}
+
+	// Positional children are replaced, keyed children may still be matched.
+	if cursor.isPositional() {
+		next := cursor.nextSibling
+		dst.replace(createNode(src, vm), cursor)
+		return next
+	}
+	return dst.place(createNode(src, vm), cursor)
+}
+
+// isPositional returns true if the node is matched by position, i.e. neither keyed nor a subcomponent.
+func (vnode *vnode) isPositional() bool {
+	return vnode != nil && vnode.key == "" && !vnode.isSub
+}
+
+// place places the child before the cursor unless the child is the cursor.
+// The next cursor is returned.
+func (vnode *vnode) place(child, cursor *vnode) *vnode {
+	if child == cursor {
+		return cursor.nextSibling
+	}
+	vnode.insertBefore(child, cursor)
+	return cursor
+}
+
+// nodeKey returns the key attribute value of the html node.
+// The key is empty if the node is not keyed.
+func nodeKey(attrs []html.Attribute) string {
+	for _, attr := range attrs {
+		if attr.Key == keyAttr {
+			return attr.Val
 		}
 	}
+	return ""
 }
 
 // renderAttributes renders the attributes.
@@ -125,6 +205,9 @@ func (vnode *vnode) renderAttributes(attrs []html.Attribute) {
 	keys := make(map[string]struct{}, len(vnode.attrs)+len(attrs))
 	srcAttrs := make(map[string]string, len(attrs))
 	for _, attr := range attrs {
+		if attr.Key == keyAttr {
+			continue
+		}
 		keys[attr.Key] = struct{}{}
 		srcAttrs[attr.Key] = attr.Val
 	}
@@ -147,8 +230,12 @@ func (vnode *vnode) renderAttributes(attrs []html.Attribute) {
 func (vnode *vnode) setAttr(key, val string) {
 	vnode.attrs[key] = val
 	if vnode.node != nil {
-		if key == "value" {
+		// Properties reflect the state of form elements, unlike attributes.
+		switch key {
+		case "value":
 			vnode.node.Underlying().Set(key, val)
+		case "checked", "selected":
+			vnode.node.Underlying().Set(key, true)
 		}
 		vnode.node.(dom.Element).SetAttribute(key, val)
 	}
@@ -158,6 +245,10 @@ func (vnode *vnode) setAttr(key, val string) {
 func (vnode *vnode) remAttr(key string) {
 	delete(vnode.attrs, key)
 	if vnode.node != nil {
+		switch key {
+		case "checked", "selected":
+			vnode.node.Underlying().Set(key, false)
+		}
 		vnode.node.(dom.Element).RemoveAttribute(key)
 	}
 }
@@ -171,7 +262,9 @@ func (vnode *vnode) setText(content string) {
 }
 
 // append appends the child to the node.
+// The child is moved if it is already attached.
 func (vnode *vnode) append(child *vnode) {
+	child.detach()
 	prev := vnode.lastChild
 	if prev == nil {
 		vnode.firstChild = child
@@ -187,8 +280,33 @@ func (vnode *vnode) append(child *vnode) {
 	}
 }
 
+// insertBefore inserts the child before the reference child, the child is appended without a reference.
+// The child is moved if it is already attached.
+func (vnode *vnode) insertBefore(child, ref *vnode) {
+	if ref == nil {
+		vnode.append(child)
+		return
+	}
+	child.detach()
+	prev := ref.prevSibling
+	if prev == nil {
+		vnode.firstChild = child
+	} else {
+		prev.nextSibling = child
+	}
+	ref.prevSibling = child
+	child.parent = vnode
+	child.prevSibling = prev
+	child.nextSibling = ref
+
+	if vnode.node != nil {
+		vnode.node.InsertBefore(child.node, ref.node)
+	}
+}
+
 // replace replaces a child with a new child.
 func (vnode *vnode) replace(newChild, oldChild *vnode) {
+	newChild.detach()
 	prev, next := oldChild.prevSibling, oldChild.nextSibling
 	if prev == nil {
 		vnode.firstChild = newChild
@@ -203,6 +321,7 @@ func (vnode *vnode) replace(newChild, oldChild *vnode) {
 	newChild.parent = vnode
 	newChild.prevSibling = prev
 	newChild.nextSibling = next
+	oldChild.parent, oldChild.prevSibling, oldChild.nextSibling = nil, nil, nil
 
 	if vnode.node != nil {
 		vnode.node.ReplaceChild(newChild.node, oldChild.node)
@@ -211,6 +330,22 @@ func (vnode *vnode) replace(newChild, oldChild *vnode) {
 
 // remove removes a child from the node.
 func (vnode *vnode) remove(child *vnode) {
+	vnode.unlink(child)
+
+	if vnode.node != nil {
+		vnode.node.RemoveChild(child.node)
+	}
+}
+
+// detach unlinks the node from its parent without removing the element.
+func (vnode *vnode) detach() {
+	if vnode.parent != nil {
+		vnode.parent.unlink(vnode)
+	}
+}
+
+// unlink unlinks a child from the node.
+func (vnode *vnode) unlink(child *vnode) {
 	if vnode.firstChild == child {
 		vnode.firstChild = child.nextSibling
 	}
@@ -223,8 +358,5 @@ func (vnode *vnode) remove(child *vnode) {
 	if child.prevSibling != nil {
 		child.prevSibling.nextSibling = child.nextSibling
 	}
-
-	if vnode.node != nil {
-		vnode.node.RemoveChild(child.node)
-	}
+	child.parent, child.prevSibling, child.nextSibling = nil, nil, nil
 }
@@ -2,58 +2,146 @@
 package vue
 
 import (
+	"golang.org/x/net/html"
 	"reflect"
 	"syscall/js"
 )
 
+// ids is the number of view models created, which identifies view models.
+var ids int
+
 // ViewModel is a vue view model, e.g. VM.
 type ViewModel struct {
-	comp  *Comp
-	vnode *vnode
-	data  reflect.Value
-	state map[string]interface{}
-	funcs map[string]js.Func
-	props map[string]interface{}
-	subs  subs
-	bus   *bus
-
-	index int
+	id     int
+	comp   *Comp
+	parent *ViewModel
+	vnode  *vnode
+	data   reflect.Value
+	state  map[string]interface{}
+	funcs  map[listener]js.Func
+	props  map[string]interface{}
+	subs   subs
+	bus    *bus
+	model  *model
+
+	watchers   []*watcher
+	typedProps reflect.Value
+	passed     map[string]interface{}
+	slots      map[string]*slot
+	slotSubs   slotSubs
+	slotted    map[*html.Node]subs
+	slotting   *ViewModel
+	listeners  map[string][]*handler
+
+	mounted   bool
+	destroyed bool
+
+	handlers  map[int]*handler
+	handlerID int
+	fired     map[onceKey]struct{}
+	selects   map[*html.Node]interface{}
+
+	tracking  *tracking
+	computeds map[string]*tracking
+	cache     map[*tmplNode]*tracked
+	rendered  map[*tmplNode]*tracked
+	dirty     map[string]struct{}
+	changed   map[string]struct{}
+	untracked bool
+	full      bool
 }
 
 // New creates a new view model from the given options.
 func New(options ...Option) *ViewModel {
 	comp := Component(options...)
-	return newViewModel(comp, nil, nil)
+	return newViewModel(comp, nil, nil, nil)
 }
 
-// newViewModel creates a new view model from the given component with props.
-func newViewModel(comp *Comp, bus *bus, props map[string]interface{}) *ViewModel {
+// newViewModel creates a new view model from the given component with props and slots.
+// Errors are handled once the view model is created, rendering a fallback instead.
+func newViewModel(comp *Comp, parent *ViewModel, props map[string]interface{}, slots map[string]*slot) *ViewModel {
 	var vnode *vnode
+	var errs []error
 	if comp.isSub {
-		vnode = newSubNode(comp.tmpl)
+		var err error
+		vnode, err = newSubNode(comp)
+		errs = append(errs, err)
 	} else {
 		vnode = newNode(comp.el)
 	}
-	data := comp.newData()
-	funcs := make(map[string]js.Func, 0)
+	data, err := comp.newData()
+	errs = append(errs, err)
+	funcs := make(map[listener]js.Func, 0)
+	selects := make(map[*html.Node]interface{}, 0)
+	fired := make(map[onceKey]struct{}, 0)
+	slotSubs := make(slotSubs, 0)
+	state := make(map[string]interface{}, 0)
+	dirty := make(map[string]struct{}, 0)
+	computeds := make(map[string]*tracking, 0)
 	subs := newSubs(comp.subs)
 
+	ids++
 	vm := &ViewModel{
-		comp:  comp,
-		vnode: vnode,
-		data:  data,
-		funcs: funcs,
-		props: props,
-		subs:  subs,
+		id:        ids,
+		comp:      comp,
+		parent:    parent,
+		vnode:     vnode,
+		data:      data,
+		state:     state,
+		funcs:     funcs,
+		selects:   selects,
+		fired:     fired,
+		props:     props,
+		slots:     slots,
+		slotSubs:  slotSubs,
+		subs:      subs,
+		dirty:     dirty,
+		computeds: computeds,
+		untracked: true,
+	}
+	var bus *bus
+	if parent != nil {
+		bus = parent.bus
 	}
 	vm.bus = newBus(bus, vm)
+	for _, def := range comp.watchers {
+		if err := vm.checkWatcher(def); err != nil {
+			errs = append(errs, vm.newError(err, "", "Watch "+def.path))
+			continue
+		}
+		watcher := *def
+		vm.watchers = append(vm.watchers, &watcher)
+	}
+	for computed, setter := range comp.setters {
+		if err := checkSetter(setter); err != nil {
+			errs = append(errs, vm.newError(err, "", "ComputedSetter "+computed))
+		}
+	}
+	for _, err := range errs {
+		if err != nil {
+			vm.handleError(err)
+		}
+	}
+	if comp.isSub {
+		vm.props = vm.validateProps(props)
+	}
+	// State is mapped for the created hook, e.g. to read fields by Get.
+	vm.mapState()
+	vm.callHook(created)
 	vm.render()
+	// Subcomponents are mounted when rendered by the parent.
+	if !comp.isSub {
+		vm.mount()
+	}
 	return vm
 }
 
-// must panics on errors.
-func must(err error) {
+// console is the browser console.
+var console = js.Global().Get("console")
+
+// warn reports errors without panicking, e.g. invalid input.
+func warn(err error) {
 	if err != nil {
-		panic(err)
+		console.Call("error", err.Error())
 	}
 }
@@ -0,0 +1,255 @@
+package vue
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// maxWatches is the maximum number of rounds of watchers called before a render.
+// Watchers which set watched fields are called again by the next round.
+const maxWatches = 100
+
+// WatchOption is an option for watchers.
+type WatchOption func(*watcher)
+
+// Deep is the deep watch option.
+// The watcher is called on changes of nested fields, elements and entries of the value,
+// e.g. slices mutated without Set. The old value is a copy of the value.
+func Deep() WatchOption {
+	return func(watcher *watcher) {
+		watcher.deep = true
+	}
+}
+
+// Immediate is the immediate watch option.
+// The watcher is called with the initial value before the first render.
+// The old value is the zero value.
+func Immediate() WatchOption {
+	return func(watcher *watcher) {
+		watcher.immediate = true
+	}
+}
+
+// watcher calls the function on changes of the value of the field path, e.g. User.Address.City.
+type watcher struct {
+	path      string
+	field     string
+	function  reflect.Value
+	deep      bool
+	immediate bool
+
+	old     interface{}
+	started bool
+	stopped bool
+}
+
+// newWatcher creates a new watcher of the field path with the function and options.
+func newWatcher(path string, function interface{}, options []WatchOption) *watcher {
+	watcher := &watcher{
+		path:     path,
+		field:    strings.Split(path, ".")[0],
+		function: reflect.ValueOf(function),
+	}
+	for _, option := range options {
+		option(watcher)
+	}
+	return watcher
+}
+
+// Watch watches the field path with the function and options.
+// The returned function unwatches the field path.
+// For example: unwatch := vctx.Watch("User.Name", func(vctx vue.Context, newVal, oldVal string) {...})
+func (vm *ViewModel) Watch(field string, function interface{}, options ...WatchOption) func() {
+	watcher := newWatcher(field, function, options)
+	if err := vm.checkWatcher(watcher); err != nil {
+		vm.handleError(vm.newError(err, "", "Watch "+field))
+		return func() {}
+	}
+
+	vm.watchers = append(vm.watchers, watcher)
+	if _, ok := vm.state[watcher.field]; ok {
+		vm.startWatcher(watcher)
+	}
+	return func() {
+		vm.unwatch(watcher)
+	}
+}
+
+// unwatch stops and removes the watcher.
+func (vm *ViewModel) unwatch(watcher *watcher) {
+	watcher.stopped = true
+	for i, w := range vm.watchers {
+		if w == watcher {
+			vm.watchers = append(vm.watchers[:i], vm.watchers[i+1:]...)
+			return
+		}
+	}
+}
+
+// checkWatcher returns an error if the watcher is not a field path with a function.
+func (vm *ViewModel) checkWatcher(watcher *watcher) error {
+	x, err := vm.comp.expr(watcher.path)
+	if err != nil {
+		return err
+	}
+	if !isPath(x) {
+		return fmt.Errorf("watch value is not a field path: %s", watcher.path)
+	}
+	if watcher.function.Kind() != reflect.Func {
+		return fmt.Errorf("watcher is not a function: %s", watcher.function.Type())
+	}
+	return nil
+}
+
+// callWatchers calls the watchers of changed values.
+// Only watchers of changed fields are checked, unless data was mutated without Set.
+// Returns true if a watcher was called.
+func (vm *ViewModel) callWatchers() bool {
+	called := false
+	for _, watcher := range append([]*watcher(nil), vm.watchers...) {
+		if watcher.stopped {
+			continue
+		}
+		if !watcher.started {
+			called = vm.startWatcher(watcher) || called
+			continue
+		}
+		if _, ok := vm.dirty[watcher.field]; !ok && !vm.untracked {
+			continue
+		}
+
+		value, err := vm.eval(watcher.path, newScope(vm.state))
+		if err != nil {
+			vm.handleError(vm.newError(err, "", "watch "+watcher.path))
+			continue
+		}
+		if !watcher.changed(value) {
+			continue
+		}
+		old := watcher.old
+		watcher.old = watcher.snapshot(value)
+		vm.watch(watcher, value, old)
+		called = true
+	}
+	return called
+}
+
+// startWatcher starts the watcher with the initial value.
+// Returns true if the watcher is immediate and called.
+func (vm *ViewModel) startWatcher(watcher *watcher) bool {
+	value, err := vm.eval(watcher.path, newScope(vm.state))
+	if err != nil {
+		vm.handleError(vm.newError(err, "", "watch "+watcher.path))
+		return false
+	}
+	watcher.started = true
+	watcher.old = watcher.snapshot(value)
+	if watcher.immediate {
+		vm.watch(watcher, value, nil)
+	}
+	return watcher.immediate
+}
+
+// watch calls the function of the watcher with the new and old values.
+// The values are converted into the parameter types of the function, nil is the zero value.
+func (vm *ViewModel) watch(watcher *watcher, newVal, oldVal interface{}) {
+	defer vm.catch("watch " + watcher.path)
+	values, err := convertArgs(watcher.function.Type(), 1, []interface{}{newVal, oldVal})
+	if err != nil {
+		vm.handleError(vm.newError(err, "", "watch "+watcher.path))
+		return
+	}
+	watcher.function.Call(append([]reflect.Value{reflect.ValueOf(vm)}, values...))
+}
+
+// changed returns true if the value changed since the old value.
+// Deep watchers compare nested values, other watchers compare references by identity.
+func (watcher *watcher) changed(value interface{}) bool {
+	if watcher.deep {
+		return !reflect.DeepEqual(watcher.old, value)
+	}
+	return !sameValue(watcher.old, value)
+}
+
+// snapshot returns the value to be compared by later changes.
+// Deep watchers copy the value since nested values may be mutated.
+func (watcher *watcher) snapshot(value interface{}) interface{} {
+	if !watcher.deep || value == nil {
+		return value
+	}
+	return copyValue(reflect.ValueOf(value)).Interface()
+}
+
+// sameValue returns true if the values are equal, references are compared by identity.
+// For example, slices are the same with the same elements and length.
+func sameValue(a, b interface{}) bool {
+	aVal, bVal := reflect.ValueOf(a), reflect.ValueOf(b)
+	if !aVal.IsValid() || !bVal.IsValid() || aVal.Type() != bVal.Type() {
+		return aVal.IsValid() == bVal.IsValid() && !aVal.IsValid()
+	}
+	switch aVal.Kind() {
+	case reflect.Slice:
+		return aVal.Pointer() == bVal.Pointer() && aVal.Len() == bVal.Len()
+	case reflect.Map, reflect.Ptr, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return aVal.Pointer() == bVal.Pointer()
+	default:
+		return reflect.DeepEqual(a, b)
+	}
+}
+
+// copyValue returns a deep copy of the value with copies of nested pointers, slices and maps.
+// Unexported fields are copied shallowly, values are required to be acyclic.
+func copyValue(value reflect.Value) reflect.Value {
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() {
+			return value
+		}
+		elem := reflect.New(value.Type().Elem())
+		elem.Elem().Set(copyValue(value.Elem()))
+		return elem
+	case reflect.Interface:
+		if value.IsNil() {
+			return value
+		}
+		elem := reflect.New(value.Type()).Elem()
+		elem.Set(copyValue(value.Elem()))
+		return elem
+	case reflect.Slice:
+		if value.IsNil() {
+			return value
+		}
+		elems := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+		for i := 0; i < value.Len(); i++ {
+			elems.Index(i).Set(copyValue(value.Index(i)))
+		}
+		return elems
+	case reflect.Array:
+		elems := reflect.New(value.Type()).Elem()
+		for i := 0; i < value.Len(); i++ {
+			elems.Index(i).Set(copyValue(value.Index(i)))
+		}
+		return elems
+	case reflect.Map:
+		if value.IsNil() {
+			return value
+		}
+		entries := reflect.MakeMapWithSize(value.Type(), value.Len())
+		for _, key := range value.MapKeys() {
+			entries.SetMapIndex(key, copyValue(value.MapIndex(key)))
+		}
+		return entries
+	case reflect.Struct:
+		fields := reflect.New(value.Type()).Elem()
+		fields.Set(value)
+		for i := 0; i < value.NumField(); i++ {
+			if field := fields.Field(i); field.CanSet() {
+				field.Set(copyValue(value.Field(i)))
+			}
+		}
+		return fields
+	default:
+		return value
+	}
+}